@@ -2,14 +2,26 @@ package main
 
 import (
 	"bufio"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"io/ioutil"
 
@@ -18,8 +30,9 @@ import (
 
 // Contract: captures contract high-level information, such as its identification number, duration, and status.
 type Contract struct {
-	ID               string `json:"ID"`     // This is the ID that will identify this contract in the ledger.
-	Status           string `json:"Status"` // Can only be Pending, Active, and Terminated. In next version we will add Rejected, and Completed.
+	ID               string `json:"ID"`       // This is the ID that will identify this contract in the ledger.
+	Status           string `json:"Status"`   // Can be Pending, Active, Rejected, Suspended, Completed, or Terminated.
+	Revision         int    `json:"Revision"` // Goes up by one with every change. Changes are made against the revision they were read at.
 	Notes            string `json:"Notes"`
 	StartDate        string `json:"Start date"`
 	EndDate          string `json:"End date"`
@@ -29,6 +42,134 @@ type Contract struct {
 	Job              Job
 	Benefits         Benefits
 	Disputes         []Dispute
+	// RequiredApprovals lists the parties that must approve before the contract becomes Active.
+	RequiredApprovals []string   `json:"Required approvals,omitempty"`
+	Approvals         []Approval `json:"Approvals,omitempty"`
+	// PrivateDetailsHash is the SHA-256 of the salary and contact details kept in the private data collection.
+	PrivateDetailsHash string `json:"Private details hash,omitempty"`
+	EndedDate          string `json:"Ended date,omitempty"` // The date the contract was completed or terminated.
+	// Compliance lists the rule pack versions of the employer's and the employee's country the terms were checked against.
+	Compliance []RulePackVersion `json:"Compliance,omitempty"`
+	// Signatures holds the signatures of the employer and the employee over the terms. Both are needed before it can be approved.
+	Signatures []Signature `json:"Signatures,omitempty"`
+}
+
+// Party: an employer or an employee as registered on the ledger. Contracts take the name and the country from here.
+type Party struct {
+	Role           string `json:"Role"` // Either employer or employee.
+	ID             string `json:"ID"`   // The pseudonym of the employee ID for employees.
+	Name           string `json:"Name"`
+	Country        string `json:"Country"`
+	Status         string `json:"Status"` // Can be Pending, Verified, or Rejected. Only Verified parties can be named in a new contract.
+	Revision       int    `json:"Revision"`
+	RegisteredBy   string `json:"Registered by"`
+	RegisteredDate string `json:"Registered date"`
+	StatusBy       string `json:"Status by,omitempty"`
+	StatusDate     string `json:"Status date,omitempty"`
+}
+
+// Signature: the signature of the employer or the employee over the SHA-256 of the contract terms.
+type Signature struct {
+	Party     string `json:"Party"` // Either employer or employee.
+	PartyID   string `json:"Party ID"`
+	TermsHash string `json:"Terms hash"`
+	Signature string `json:"Signature"`
+	PublicKey string `json:"Public key"`
+	Date      string `json:"Date"`
+}
+
+// SigningTerms: the canonical terms of a contract and their hash, as returned by GetContractTerms.
+type SigningTerms struct {
+	ContractID string `json:"Contract ID"`
+	Terms      string `json:"Terms"`
+	SHA256     string `json:"SHA-256"`
+}
+
+// ContractVersion: one version of a contract as returned by GetContractHistory.
+// AmendmentTerms: the part of a contract an amendment can change.
+type AmendmentTerms struct {
+	Notes            string `json:"Notes"`
+	StartDate        string `json:"Start date"`
+	EndDate          string `json:"End date"`
+	ExtensionDetails string `json:"Extension details"`
+	Employer         Employer
+	Employee         Employee
+	Job              Job
+	Benefits         Benefits
+}
+
+// Amendment: a change to the terms of an Active or Suspended contract, waiting for the other party or for its effective date.
+type Amendment struct {
+	ID            string         `json:"ID"`
+	ContractID    string         `json:"Contract ID"`
+	Status        string         `json:"Status"`
+	ProposedBy    string         `json:"Proposed by"`
+	ProposedDate  string         `json:"Proposed date"`
+	EffectiveDate string         `json:"Effective date"`
+	DecisionDate  string         `json:"Decision date,omitempty"`
+	AppliedDate   string         `json:"Applied date,omitempty"`
+	Terms         AmendmentTerms `json:"Terms"`
+}
+
+// Payment: a salary payment the employer recorded for one month of a contract.
+type Payment struct {
+	ID            string `json:"ID"`
+	ContractID    string `json:"Contract ID"`
+	Period        string `json:"Period"` // MM/YYYY
+	Amount        Money  `json:"Amount"` // Only filled in for the parties and the authorities.
+	Currency      string `json:"Currency"`
+	Reference     string `json:"Reference"`
+	RecordedDate  string `json:"Recorded date"`
+	Confirmed     bool   `json:"Confirmed"`
+	ConfirmedDate string `json:"Confirmed date,omitempty"`
+	Rejected      bool   `json:"Rejected"`
+	RejectedDate  string `json:"Rejected date,omitempty"`
+	Reason        string `json:"Rejection reason,omitempty"`
+}
+
+// PeriodArrears: what was due and paid for one month of a contract.
+type PeriodArrears struct {
+	Period    string `json:"Period"`
+	Expected  Money  `json:"Expected"`
+	Paid      Money  `json:"Paid"`
+	Confirmed Money  `json:"Confirmed"`
+	Disputed  Money  `json:"Disputed"` // Paid, but not confirmed or rejected by the employee yet.
+	Rejected  Money  `json:"Rejected"`
+	Arrears   Money  `json:"Arrears"` // Expected minus Confirmed.
+}
+
+// ArrearsReport: the salary due on a contract so far, as returned by GetArrears.
+type ArrearsReport struct {
+	ContractID     string          `json:"Contract ID"`
+	Currency       string          `json:"Currency"`
+	Periods        []PeriodArrears `json:"Periods"`
+	TotalExpected  Money           `json:"Total expected"`
+	TotalPaid      Money           `json:"Total paid"`
+	TotalConfirmed Money           `json:"Total confirmed"`
+	TotalDisputed  Money           `json:"Total disputed"`
+	TotalRejected  Money           `json:"Total rejected"`
+	TotalArrears   Money           `json:"Total arrears"`
+}
+
+// DisputeVersion: one version of a dispute, as returned by GetDisputeHistory.
+type DisputeVersion struct {
+	TxID      string   `json:"Tx ID"`
+	Timestamp string   `json:"Timestamp"`
+	IsDelete  bool     `json:"Is delete"`
+	Dispute   *Dispute `json:"Dispute,omitempty"`
+}
+
+type ContractVersion struct {
+	TxID      string    `json:"Tx ID"`
+	Timestamp string    `json:"Timestamp"`
+	IsDelete  bool      `json:"Is delete"`
+	Contract  *Contract `json:"Contract,omitempty"`
+}
+
+// Approval: records that one of the required parties approved the contract.
+type Approval struct {
+	Party string `json:"Party"`
+	Date  string `json:"Date"`
 }
 
 // Employer: provides data about the employer, such as name and address details
@@ -41,37 +182,349 @@ type Employer struct {
 
 // Employee: provides data about the employee, such as name and contact details
 type Employee struct {
-	ID         string `json:"ID"`
+	ID         string `json:"ID"` // The pseudonym of the employee in contracts read from the ledger.
 	Name       string `json:"Name"`
 	EmployeeAC string `json:"Employee address and contact details"`
 	Country    string `json:"Country"`
+	Identifier string `json:"Identifier,omitempty"` // The cleartext employee ID, for the parties allowed to read the private details.
 }
 
 // Job: describes the job details, such as position and task description
 type Job struct {
-	Position    string `json:"Position"`
-	Level       string `json:"Level"`
-	Description string `json:"Description"`
+	Position        string `json:"Position"`
+	Level           string `json:"Level"`
+	Description     string `json:"Description"`
+	ProbationMonths int    `json:"Probation months,omitempty"`
 }
 
 // Benefits: states the job benefits, such salary, allowances, and annual increase
 type Benefits struct {
-	Currency       string `json:"Currency"`
-	Salary         int    `json:"Salary"`
-	AnnualIncrease string `json:"Annual increase"`
-	AnnualLeave    string `json:"Annual leave"`
-	Housing        int    `json:"Housing"`
-	Allowances     int    `json:"Allowances"`
-	OtherBenefits  string `json:"Other benefits"`
+	Currency       string         `json:"Currency"`
+	Salary         Money          `json:"Salary"`
+	AnnualIncrease AnnualIncrease `json:"Annual increase"`
+	AnnualLeave    AnnualLeave    `json:"Annual leave"`
+	Housing        Money          `json:"Housing"`
+	Allowances     Money          `json:"Allowances"`
+	OtherBenefits  string         `json:"Other benefits"`
+}
+
+// Money: an amount in the minor units of an ISO 4217 currency, so 1050 minor units of SAR are 10.50 SAR.
+// Contract files can also hold bare numbers, which are read as major units of the contract currency the same way as the chaincode does.
+type Money struct {
+	MinorUnits int64  `json:"Minor units"`
+	Currency   string `json:"Currency"`
+}
+
+// currencyExponents: the ISO 4217 currencies that don't have 2 decimals. The chaincode checks the codes themselves.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+func currencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var major int64
+	if err := json.Unmarshal(data, &major); err == nil {
+		*m = Money{MinorUnits: major}
+		return nil
+	}
+	type plainMoney Money
+	var money plainMoney
+	if err := json.Unmarshal(data, &money); err != nil {
+		return err
+	}
+	*m = Money(money)
+	return nil
+}
+
+// Will convert a bare number read from an older file to the minor units of the currency.
+func (m *Money) migrate(currency string) {
+	if m.Currency != "" || currency == "" {
+		return
+	}
+	for i := 0; i < currencyExponent(currency); i++ {
+		m.MinorUnits *= 10
+	}
+	m.Currency = currency
+}
+
+// Will show the amount in major units followed by the currency, for example "10000.50 SAR".
+func (m Money) String() string {
+	exponent := currencyExponent(m.Currency)
+	sign := ""
+	units := m.MinorUnits
+	if units < 0 {
+		sign, units = "-", -units
+	}
+	text := strconv.FormatInt(units, 10)
+	if exponent > 0 {
+		for len(text) <= exponent {
+			text = "0" + text
+		}
+		text = text[:len(text)-exponent] + "." + text[len(text)-exponent:]
+	}
+	return strings.TrimSpace(sign + text + " " + m.Currency)
+}
+
+// Will read an amount typed in major units, such as "5000" or "5000.50", in the given currency.
+func parseMoney(text string, currency string) (Money, error) {
+	exponent := currencyExponent(currency)
+	whole, fraction := strings.TrimSpace(text), ""
+	if dot := strings.Index(whole, "."); dot >= 0 {
+		whole, fraction = whole[:dot], whole[dot+1:]
+	}
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("%s has at most %d decimals", currency, exponent)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{MinorUnits: units, Currency: currency}, nil
+}
+
+func (b *Benefits) UnmarshalJSON(data []byte) error {
+	type plainBenefits Benefits
+	var benefits plainBenefits
+	if err := json.Unmarshal(data, &benefits); err != nil {
+		return err
+	}
+	*b = Benefits(benefits)
+	b.Salary.migrate(b.Currency)
+	b.Housing.migrate(b.Currency)
+	b.Allowances.migrate(b.Currency)
+	if b.AnnualIncrease.Amount != nil {
+		b.AnnualIncrease.Amount.migrate(b.Currency)
+	}
+	if b.AnnualIncrease.Cap != nil {
+		b.AnnualIncrease.Cap.migrate(b.Currency)
+	}
+	return nil
+}
+
+// AnnualIncrease: the rule the salary grows by every year. Type can be none, percent, or fixed.
+// Contract files can also hold free text such as "5%", which is read the same way as the chaincode does.
+type AnnualIncrease struct {
+	Type             string  `json:"Type"`
+	Percent          float64 `json:"Percent,omitempty"`
+	Amount           *Money  `json:"Amount,omitempty"`
+	AnniversaryMonth int     `json:"Anniversary month,omitempty"` // 1 to 12. Empty means the month of the start date.
+	Cap              *Money  `json:"Cap,omitempty"`
+	Description      string  `json:"Description,omitempty"`
+}
+
+func (a *AnnualIncrease) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*a = parseIncrease(text)
+		return nil
+	}
+	type plainIncrease AnnualIncrease
+	var increase plainIncrease
+	if err := json.Unmarshal(data, &increase); err != nil {
+		return err
+	}
+	*a = AnnualIncrease(increase)
+	return nil
+}
+
+// Will read a free text increase: "5%" is a percent increase, "500" a fixed one, and any other text has no increase.
+func parseIncrease(text string) AnnualIncrease {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return AnnualIncrease{}
+	}
+	if number := strings.TrimSpace(strings.TrimSuffix(trimmed, "%")); number != trimmed {
+		if percent, err := strconv.ParseFloat(number, 64); err == nil && percent > 0 {
+			return AnnualIncrease{Type: "percent", Percent: percent, Description: text}
+		}
+	} else if amount, err := strconv.ParseInt(trimmed, 10, 64); err == nil && amount > 0 {
+		return AnnualIncrease{Type: "fixed", Amount: &Money{MinorUnits: amount}, Description: text}
+	}
+	return AnnualIncrease{Type: "none", Description: text}
+}
+
+// Will describe the increase in one line, for example "5% every July, capped at 12000".
+func (a AnnualIncrease) String() string {
+	when := "every year"
+	if a.AnniversaryMonth >= 1 && a.AnniversaryMonth <= 12 {
+		when = "every " + time.Month(a.AnniversaryMonth).String()
+	}
+	var text string
+	switch a.Type {
+	case "percent":
+		text = strconv.FormatFloat(a.Percent, 'f', -1, 64) + "% " + when
+	case "fixed":
+		amount := "?"
+		if a.Amount != nil {
+			amount = a.Amount.String()
+		}
+		text = "+" + amount + " " + when
+	default:
+		if a.Description != "" {
+			return a.Description
+		}
+		return "None"
+	}
+	if a.Cap != nil {
+		text += ", capped at " + a.Cap.String()
+	}
+	return text
+}
+
+// AnnualLeave: the paid leave days a contract grants per year of service.
+// Contract files can also hold free text such as "30 days", which is read the same way as the chaincode does.
+type AnnualLeave struct {
+	Days        int    `json:"Days"`
+	Description string `json:"Description,omitempty"`
+}
+
+func (a *AnnualLeave) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		digits := strings.TrimSpace(text)
+		if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			digits = digits[:end]
+		}
+		days, _ := strconv.Atoi(digits)
+		*a = AnnualLeave{Days: days, Description: text}
+		return nil
+	}
+	type plainLeave AnnualLeave
+	var leave plainLeave
+	if err := json.Unmarshal(data, &leave); err != nil {
+		return err
+	}
+	*a = AnnualLeave(leave)
+	return nil
+}
+
+func (a AnnualLeave) String() string {
+	return strconv.Itoa(a.Days) + " days a year"
+}
+
+// LeaveRequest: a period of annual leave the employee asked for. Status can be Pending, Approved, or Denied.
+type LeaveRequest struct {
+	ID            string `json:"ID"`
+	ContractID    string `json:"Contract ID"`
+	Status        string `json:"Status"`
+	From          string `json:"From"`
+	To            string `json:"To"`
+	Days          int    `json:"Days"`
+	Reason        string `json:"Reason"`
+	RequestedDate string `json:"Requested date"`
+	DecisionDate  string `json:"Decision date,omitempty"`
+	DenialReason  string `json:"Denial reason,omitempty"`
+	DisputeID     string `json:"Dispute ID,omitempty"`
+}
+
+// LeaveBalance: the annual leave of a contract today, as returned by GetLeaveBalance.
+type LeaveBalance struct {
+	ContractID  string `json:"Contract ID"`
+	Date        string `json:"Date"`
+	DaysPerYear int    `json:"Days per year"`
+	Accrued     int    `json:"Accrued"`
+	Taken       int    `json:"Taken"`
+	Requested   int    `json:"Requested"`
+	Available   int    `json:"Available"`
+}
+
+// SalaryYear: the projected pay over one contract year, as returned by GetSalarySchedule.
+type SalaryYear struct {
+	Year            int    `json:"Year"`
+	From            string `json:"From"`
+	To              string `json:"To"`
+	Months          int    `json:"Months"`
+	MonthlySalary   Money  `json:"Monthly salary"`
+	MonthlyGrossPay Money  `json:"Monthly gross pay"`
+	GrossPay        Money  `json:"Gross pay"`
+}
+
+// SalarySchedule: the projected pay of a contract from its start date to its end date.
+type SalarySchedule struct {
+	ContractID     string         `json:"Contract ID"`
+	Currency       string         `json:"Currency"`
+	AnnualIncrease AnnualIncrease `json:"Annual increase"`
+	Years          []SalaryYear   `json:"Years"`
+	TotalGrossPay  Money          `json:"Total gross pay"`
+}
+
+// RulePack: the labor rules of one country, as set by its regulators with SetRulePack. A rule left empty is not enforced.
+type RulePack struct {
+	Country            string         `json:"Country"`
+	Version            int            `json:"Version"` // Set by the chaincode. 0 means the country has no rules yet.
+	MinimumSalaries    []LevelMinimum `json:"Minimum salaries"`
+	MaxContractMonths  int            `json:"Max contract months,omitempty"`
+	MinimumHousing     *Money         `json:"Minimum housing,omitempty"`
+	MinimumLeaveDays   int            `json:"Minimum annual leave days,omitempty"`
+	MaxProbationMonths int            `json:"Max probation months,omitempty"`
+	UpdatedBy          string         `json:"Updated by,omitempty"`
+	UpdatedDate        string         `json:"Updated date,omitempty"`
+}
+
+// LevelMinimum: the lowest monthly salary for a job level, or for every other level if the level is "*".
+type LevelMinimum struct {
+	Level   string `json:"Level"`
+	Minimum Money  `json:"Minimum"`
+}
+
+// RulePackVersion: the version of a country's rule pack a contract was checked against.
+type RulePackVersion struct {
+	Country string `json:"Country"`
+	Version int    `json:"Version"`
+}
+
+// Document: an off-chain file anchored to a contract by its SHA-256 hash, as returned by GetDocuments.
+type Document struct {
+	ID           string `json:"ID"`
+	ContractID   string `json:"Contract ID"`
+	Type         string `json:"Type"` // Can be offer letter, passport, payslip, visa, or other.
+	SHA256       string `json:"SHA-256"`
+	URI          string `json:"URI"`
+	AttachedBy   Caller `json:"Attached by"`
+	AttachedDate string `json:"Attached date"`
+}
+
+// Caller: the identity that made a change, as read by the chaincode from its certificate.
+type Caller struct {
+	MSPID   string
+	Role    string
+	PartyID string
 }
 
 // Disputes: lists the disputes, if any, that is raised by the employee with their content and the last update dates.
 type Dispute struct {
 	ID              string `json:"ID"`
-	Status          string `json:"Status"` // Can only be Active or Closed.
+	ContractID      string `json:"Contract ID"`
+	Revision        int    `json:"Revision"`
+	Status          string `json:"Status"`   // Can be Active, Escalated, Ruled, or Closed.
+	Category        string `json:"Category"` // One of disputeCategories.
+	Severity        string `json:"Severity"` // One of disputeSeverities.
+	ResponseDueDate string `json:"Response due date"`
 	LastUpdatedDate string `json:"Last updated date"`
 	Content         string `json:"Content"`
 	Responses       []Response
+	EscalatedDate   string  `json:"Escalated date,omitempty"`
+	Ruling          *Ruling `json:"Ruling,omitempty"`
+	LeaveRequestID  string  `json:"Leave request ID,omitempty"` // Set on disputes filed with DisputeLeave.
+}
+
+// Ruling: the decision of an arbitrator on an escalated dispute.
+type Ruling struct {
+	Arbitrator   string `json:"Arbitrator"`
+	Decision     string `json:"Decision"`
+	Date         string `json:"Date"`
+	Enforced     bool   `json:"Enforced"`
+	EnforcedBy   string `json:"Enforced by,omitempty"`
+	EnforcedDate string `json:"Enforced date,omitempty"`
 }
 
 // Responses: list the employer responses, if any, for disputes raised by the employee.
@@ -81,25 +534,65 @@ type Response struct {
 	Content         string `json:"Content"`
 }
 
-func printScreen() {
-	options := []string{
-		"1. Add Contract",
-		"2. Approve Contract",
-		"3. Update Contract",
-		"4. Extend Contract",
-		"5. Terminate Contract",
-		"6. Issue Dispute",
-		"7. Update Dispute",
-		"8. Close Dispute",
-		"9. Respond to Dispute",
-		"10. Read Contract",
-		"11. View Employee History",
-		"12. View Employer History",
-		"13. Get All Contracts",
-	}
+var options = []string{
+	"1. Add Contract",
+	"2. Approve Contract",
+	"3. Update Contract",
+	"4. Extend Contract",
+	"5. Terminate Contract",
+	"6. Issue Dispute",
+	"7. Update Dispute",
+	"8. Close Dispute",
+	"9. Respond to Dispute",
+	"10. Read Contract",
+	"11. View Employee History",
+	"12. View Employer History",
+	"13. Get All Contracts",
+	"14. Withdraw Approval",
+	"15. Reject Contract",
+	"16. Suspend Contract",
+	"17. Resume Contract",
+	"18. Complete Contract",
+	"19. Complete Expired Contracts",
+	"20. Contract History",
+	"21. Query Contracts",
+	"22. View Disputes",
+	"23. Overdue Disputes",
+	"24. Escalate Dispute",
+	"25. Record Ruling",
+	"26. Enforce Ruling",
+	"27. Dispute History",
+	"28. Propose Amendment",
+	"29. Pending Amendments",
+	"30. Accept Amendment",
+	"31. Reject Amendment",
+	"32. Record Payment",
+	"33. Confirm Payment",
+	"34. Payments and Arrears",
+	"35. Salary Schedule",
+	"36. Request Leave",
+	"37. Approve Leave",
+	"38. Deny Leave",
+	"39. Leave Balance",
+	"40. Dispute Leave",
+	"41. Set Rule Pack",
+	"42. View Rule Pack",
+	"43. Attach Document",
+	"44. Verify Document",
+	"45. View Documents",
+	"46. Generate Signing Key",
+	"47. Sign Contract",
+	"48. Register Party",
+	"49. Update Party",
+	"50. Verify Party",
+	"51. View Party",
+	"52. Pseudonymize Employees",
+	"53. Reject Payment",
+}
 
+func printScreen() {
 	numCols := 3
-	numRows := 5
+	numRows := (len(options) + numCols - 1) / numCols
 
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetBorder(true)
@@ -146,12 +639,12 @@ func choice() int {
 
 	methodNumber, err := strconv.Atoi(choice)
 	if err != nil {
-		fmt.Printf("Invalid input. Please enter a number between 1 and 15. \n")
+		fmt.Printf("Invalid input. Please enter a number between 1 and %d. \n", len(options))
 		return 0
 	}
 
-	if methodNumber < 1 || methodNumber > 15 {
-		fmt.Printf("Invalid input. Please enter a number between 1 and 15. \n")
+	if methodNumber < 1 || methodNumber > len(options) {
+		fmt.Printf("Invalid input. Please enter a number between 1 and %d. \n", len(options))
 		return 0
 	}
 	return methodNumber
@@ -159,6 +652,77 @@ func choice() int {
 }
 
 func main() {
+	// Commands for scripts, so they can run without the menu. For example: go run Main/Main.go sweep 100
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sweep":
+			batchSize := defaultSweepBatch
+			if len(os.Args) > 2 {
+				size, err := strconv.Atoi(os.Args[2])
+				if err != nil || size < 1 {
+					fmt.Println("Invalid batch size. Please enter a positive number.")
+					os.Exit(1)
+				}
+				batchSize = size
+			}
+			if !sweepContracts(batchSize) {
+				os.Exit(1)
+			}
+		case "reindex":
+			if !reindexContracts() {
+				os.Exit(1)
+			}
+		case "pseudonymize":
+			if !pseudonymizeEmployees() {
+				os.Exit(1)
+			}
+		case "attach":
+			if len(os.Args) != 5 {
+				fmt.Println("Usage: attach <contract ID> <document type> <file>")
+				os.Exit(1)
+			}
+			if !attachDocument(os.Args[2], os.Args[3], os.Args[4]) {
+				os.Exit(1)
+			}
+		case "keygen":
+			if len(os.Args) > 3 {
+				fmt.Println("Usage: keygen [ed25519 | ecdsa]")
+				os.Exit(1)
+			}
+			algorithm := "ed25519"
+			if len(os.Args) == 3 {
+				algorithm = os.Args[2]
+			}
+			if !generateSigningKey(algorithm) {
+				os.Exit(1)
+			}
+		case "sign":
+			if len(os.Args) != 3 {
+				fmt.Println("Usage: sign <contract ID>")
+				os.Exit(1)
+			}
+			if !signContract(os.Args[2]) {
+				os.Exit(1)
+			}
+		case "verify":
+			if len(os.Args) != 4 && len(os.Args) != 5 {
+				fmt.Println("Usage: verify <contract ID> <document ID> [file]")
+				os.Exit(1)
+			}
+			fileName := ""
+			if len(os.Args) == 5 {
+				fileName = os.Args[4]
+			}
+			if !verifyDocument(os.Args[2], os.Args[3], fileName) {
+				os.Exit(1)
+			}
+		default:
+			fmt.Printf("Unknown command %s. The commands are: sweep [batch size], reindex, pseudonymize, attach, verify, keygen, sign \n", os.Args[1])
+			os.Exit(1)
+		}
+		return
+	}
+
 	for j := 0; j < 20; j++ { // the program will loop for 20 times only
 		printScreen()
 		var input int = 0
@@ -213,7 +777,127 @@ func main() {
 			viewEmployerHistory()
 		case 13:
 			fmt.Println("You selected to execute view all contracts transaction ")
-			prettifyAllContracts(getAllContracts())
+			browseAllContracts()
+		case 14:
+			fmt.Println("You selected to execute withdraw approval transaction ")
+			withdrawApproval()
+		case 15:
+			fmt.Println("You selected to execute reject contract transaction ")
+			changeContractStatus("RejectContract", "The contract has been rejected.")
+		case 16:
+			fmt.Println("You selected to execute suspend contract transaction ")
+			changeContractStatus("SuspendContract", "The contract has been suspended.")
+		case 17:
+			fmt.Println("You selected to execute resume contract transaction ")
+			changeContractStatus("ResumeContract", "The contract has been resumed.")
+		case 18:
+			fmt.Println("You selected to execute complete contract transaction ")
+			changeContractStatus("CompleteContract", "The contract has been completed.")
+		case 19:
+			fmt.Println("You selected to execute complete expired contracts transaction ")
+			sweepContracts(defaultSweepBatch)
+		case 20:
+			fmt.Println("You selected to execute contract history transaction ")
+			contractHistory()
+		case 21:
+			fmt.Println("You selected to execute query contracts transaction ")
+			prettifyAllContracts(queryContracts())
+		case 22:
+			fmt.Println("You selected to execute get disputes transaction ")
+			viewDisputes()
+		case 23:
+			fmt.Println("You selected to execute get overdue disputes transaction ")
+			overdueDisputes()
+		case 24:
+			fmt.Println("You selected to execute escalate dispute transaction ")
+			disputeTransaction("EscalateDispute", "The dispute has been escalated to arbitration.")
+		case 25:
+			fmt.Println("You selected to execute record ruling transaction ")
+			disputeTransaction("RecordRuling", "The ruling has been recorded.", "Enter the decision: ")
+		case 26:
+			fmt.Println("You selected to execute enforce ruling transaction ")
+			disputeTransaction("EnforceRuling", "The ruling has been enforced and the dispute closed.")
+		case 27:
+			fmt.Println("You selected to execute dispute history transaction ")
+			disputeHistory()
+		case 28:
+			fmt.Println("You selected to execute propose amendment transaction ")
+			proposeAmendment()
+		case 29:
+			fmt.Println("You selected to execute get amendments transaction ")
+			pendingAmendments()
+		case 30:
+			fmt.Println("You selected to execute accept amendment transaction ")
+			decideAmendment("AcceptAmendment", "The amendment has been accepted.")
+		case 31:
+			fmt.Println("You selected to execute reject amendment transaction ")
+			decideAmendment("RejectAmendment", "The amendment has been rejected.")
+		case 32:
+			fmt.Println("You selected to execute record salary payment transaction ")
+			recordPayment()
+		case 33:
+			fmt.Println("You selected to execute confirm payment received transaction ")
+			decidePayment("ConfirmPaymentReceived", "The payment has been confirmed.")
+		case 34:
+			fmt.Println("You selected to execute get arrears transaction ")
+			paymentsAndArrears()
+		case 35:
+			fmt.Println("You selected to execute get salary schedule transaction ")
+			salarySchedule()
+		case 36:
+			fmt.Println("You selected to execute request leave transaction ")
+			requestLeave()
+		case 37:
+			fmt.Println("You selected to execute approve leave transaction ")
+			decideLeave("ApproveLeave", "The leave has been approved.")
+		case 38:
+			fmt.Println("You selected to execute deny leave transaction ")
+			decideLeave("DenyLeave", "The leave has been denied.", "Enter the reason: ")
+		case 39:
+			fmt.Println("You selected to execute get leave balance transaction ")
+			leaveBalance()
+		case 40:
+			fmt.Println("You selected to execute dispute leave transaction ")
+			disputeLeave()
+		case 41:
+			fmt.Println("You selected to execute set rule pack transaction ")
+			setRulePack()
+		case 42:
+			fmt.Println("You selected to execute get rule pack transaction ")
+			viewRulePack()
+		case 43:
+			fmt.Println("You selected to execute attach document transaction ")
+			attachDocumentPrompt()
+		case 44:
+			fmt.Println("You selected to execute verify document transaction ")
+			verifyDocumentPrompt()
+		case 45:
+			fmt.Println("You selected to execute get documents transaction ")
+			viewDocuments()
+		case 46:
+			fmt.Println("You selected to execute register signing key transaction ")
+			generateSigningKeyPrompt()
+		case 47:
+			fmt.Println("You selected to execute sign contract transaction ")
+			signContractPrompt()
+		case 48:
+			fmt.Println("You selected to execute register party transaction ")
+			registerParty()
+		case 49:
+			fmt.Println("You selected to execute update party transaction ")
+			updateParty()
+		case 50:
+			fmt.Println("You selected to execute set party status transaction ")
+			setPartyStatus()
+		case 51:
+			fmt.Println("You selected to execute get party transaction ")
+			viewParty()
+		case 52:
+			fmt.Println("You selected to execute pseudonymize employees transaction ")
+			pseudonymizeEmployees()
+		case 53:
+			fmt.Println("You selected to execute reject payment transaction ")
+			decidePayment("RejectPayment", "The payment has been rejected.", "Enter the reason: ")
 		}
 		reader := bufio.NewReader(os.Stdin)
 		fmt.Println()
@@ -223,12 +907,40 @@ func main() {
 
 }
 
+// The chaincode checks the caller role, so the CLI must be enrolled as an identity with role and partyID attributes.
+// Every value can be overridden with an environment variable, for example FABLO_REST_URL=http://localhost:8802 for CountryB.
+func getEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func restURL() string {
+	return getEnv("FABLO_REST_URL", "http://localhost:8801")
+}
+
 // will post your request to Fablo rest api and return the response. Without changing anything.
 func postRequest(input string, methodName string) string {
+	return postRequestWithTransient(input, methodName, nil)
+}
+
+// Same as postRequest, but also sends the transient map. Transient values reach the chaincode without being written to the block.
+func postRequestWithTransient(input string, methodName string, transient map[string]string) string {
 	client := &http.Client{}
+	transientJSON := ""
+	if transient != nil {
+		transientBytes, err := json.Marshal(transient)
+		if err != nil {
+			log.Fatal(err)
+			return ""
+		}
+		transientJSON = `,
+"transient": ` + string(transientBytes)
+	}
 	var data = strings.NewReader(`{"method": "` + methodName + `",
-"args": [` + input + `]}`)
-	req, err := http.NewRequest("POST", "http://localhost:8801/invoke/my-channel/chaincode1", data)
+"args": [` + input + `]` + transientJSON + `}`)
+	req, err := http.NewRequest("POST", restURL()+"/invoke/my-channel/chaincode1", data)
 	if err != nil {
 		log.Fatal(err)
 		return ""
@@ -270,6 +982,72 @@ func printError(err string) {
 	fmt.Printf("Error: %s", err)
 }
 
+// Will return true if the chaincode rejected a change because the record changed since it was read.
+func isRevisionConflict(response string) bool {
+	return isError(response) && strings.Contains(response, "revision conflict")
+}
+
+// Will submit a change made against a revision of a contract or a dispute.
+// If someone else changed the record in the meantime, it offers to reload it and apply the change again against the new revision.
+// @Param submit posts the change against the given revision and returns the response.
+// @Param reload reads and shows the record again, and returns its current revision. It returns false if the record can't be read.
+func submitRevision(revision int, submit func(revision int) string, reload func() (int, bool)) string {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		response := submit(revision)
+		if !isRevisionConflict(response) {
+			return response
+		}
+		fmt.Println("Someone else has changed it since you read it.")
+		fmt.Print("Do you want to reload it and apply your change again? (y/n): ")
+		answer, err := reader.ReadString('\n')
+		if err != nil || strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			return response
+		}
+		current, ok := reload()
+		if !ok {
+			return response
+		}
+		revision = current
+	}
+}
+
+// Will read a contract, show it, and return its current revision.
+func contractRevision(ID string) (int, bool) {
+	contract := choseContract(combineStrings(ID))
+	if contract.ID == "" {
+		println("No matching ID in the blockchain. Please try again.")
+		return 0, false
+	}
+	prettifyTopContract(contract)
+	return contract.Revision, true
+}
+
+// Will read a dispute, show it, and return its current revision.
+func disputeRevision(ID string, DisputeID string) (int, bool) {
+	bodyText := postRequest(combineStrings(ID, ""), "GetDisputes")
+	if isError(bodyText) {
+		printError(bodyText)
+		return 0, false
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+
+	disputes := []Dispute{}
+	if err := json.Unmarshal([]byte(jsonString), &disputes); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return 0, false
+	}
+	for _, dispute := range disputes {
+		if dispute.ID == DisputeID {
+			prettifyDispute(Contract{ID: ID, Disputes: []Dispute{dispute}})
+			return dispute.Revision, true
+		}
+	}
+	fmt.Printf("The contract %s has no dispute %s. \n", ID, DisputeID)
+	return 0, false
+}
+
 func updateDispute() {
 	// Taking all the need inputs from the user
 	reader := bufio.NewReader(os.Stdin)
@@ -277,19 +1055,65 @@ func updateDispute() {
 	ID, err := reader.ReadString('\n')
 	fmt.Print("Enter Dispute ID: ")
 	DisputeID, err2 := reader.ReadString('\n')
+	if err != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+	DisputeID = strings.TrimSpace(DisputeID)
+
+	// The change is made against the revision the user is looking at.
+	revision, ok := disputeRevision(ID, DisputeID)
+	if !ok {
+		return
+	}
 
 	fmt.Print("Enter the new content of your dispute: ")
 	Content, err3 := reader.ReadString('\n')
+	if err3 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	Content = strings.ReplaceAll(Content, "\n", "")
+
+	// Will send and get a response from the blockchain
+	bodyText := submitRevision(revision, func(revision int) string {
+		return postRequest(combineStrings(ID, DisputeID, Content, strconv.Itoa(revision)), "UpdateDispute")
+	}, func() (int, bool) {
+		return disputeRevision(ID, DisputeID)
+	})
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+
+	if isError(jsonString) {
+		printError(jsonString)
+		return
+	}
+
+	println(jsonString)
+}
 
-	if err != nil || err2 != nil || err3 != nil {
+func approveContract() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	if err != nil {
 		fmt.Printf("Could not read string \n")
 	}
 
-	combinedInputs := combineStrings(ID, DisputeID, Content)
-	combinedInputs = strings.ReplaceAll(combinedInputs, "\n", "")
+	ID = strings.TrimSpace(ID)
+
+	// The approval is given to the revision the user is looking at.
+	revision, ok := contractRevision(ID)
+	if !ok {
+		return
+	}
+	bodyText := submitRevision(revision, func(revision int) string {
+		return postRequest(combineStrings(ID, strconv.Itoa(revision)), "ApproveContract")
+	}, func() (int, bool) {
+		return contractRevision(ID)
+	})
 
-	// Will send and get a response from the blockchain
-	bodyText := postRequest(combinedInputs, "UpdateDispute")
 	jsonString := string(bodyText)
 	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
 	jsonString = strings.TrimSuffix(jsonString, "}")
@@ -299,10 +1123,17 @@ func updateDispute() {
 		return
 	}
 
-	println(jsonString)
+	contract := choseContract(ID)
+	if contract.Status == "Active" {
+		println("Contract has been approved by every required party and is now Active.")
+	} else {
+		println("Your approval has been recorded. The contract is waiting for the other approvals.")
+	}
+	prettifyTopContract(contract)
+
 }
 
-func approveContract() {
+func withdrawApproval() {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("Enter Contract ID: ")
 	ID, err := reader.ReadString('\n')
@@ -312,7 +1143,7 @@ func approveContract() {
 
 	ID = combineStrings(ID)
 	ID = strings.ReplaceAll(ID, "\n", "")
-	bodyText := postRequest(ID, "ApproveContract")
+	bodyText := postRequest(ID, "WithdrawApproval")
 
 	jsonString := string(bodyText)
 	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
@@ -323,9 +1154,8 @@ func approveContract() {
 		return
 	}
 
-	println("Contract has been approved.")
+	println("Your approval has been withdrawn.")
 	prettifyTopContract(choseContract(ID))
-
 }
 
 func extendContract() {
@@ -335,6 +1165,13 @@ func extendContract() {
 	if err != nil {
 		fmt.Printf("Could not read string \n")
 	}
+	ID = strings.ReplaceAll(ID, "\n", "")
+
+	// The change is made against the revision the user is looking at.
+	revision, ok := contractRevision(ID)
+	if !ok {
+		return
+	}
 
 	fmt.Print("Enter the extension date in the following format: 01/01/2023. You must add the 0 in 01:  ")
 	ToDate, err := reader.ReadString('\n')
@@ -343,15 +1180,14 @@ func extendContract() {
 	}
 
 	ToDate = strings.ReplaceAll(ToDate, "\n", "")
-	ID = strings.ReplaceAll(ID, "\n", "")
-
-	input := combineStrings(ID, ToDate)
-	input = strings.ReplaceAll(input, "\n", "")
 
-	bodyText := postRequest(input, "ExtendContract")
+	bodyText := submitRevision(revision, func(revision int) string {
+		return postRequest(combineStrings(ID, ToDate, strconv.Itoa(revision)), "ExtendContract")
+	}, func() (int, bool) {
+		return contractRevision(ID)
+	})
 
-	jsonString := string(bodyText)
-	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
 	jsonString = strings.TrimSuffix(jsonString, "}")
 
 	if isError(jsonString) {
@@ -392,29 +1228,20 @@ func terminateContract() {
 
 }
 
-func respondToDispute() {
-	// Taking all the need inputs from the user
+// Used by the transactions that only take a contract ID and change its status.
+// @Param methodName is the chaincode transaction, and message is printed when it succeeds.
+func changeContractStatus(methodName string, message string) {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("Enter Contract ID: ")
 	ID, err := reader.ReadString('\n')
-	fmt.Print("Enter Dispute ID: ")
-	DisputeID, err2 := reader.ReadString('\n')
-
-	// fmt.Print("Enter Response ID: ")
-	// RID, err4 := reader.ReadString('\n')
-
-	fmt.Print("Enter the content of your response: ")
-	Content, err3 := reader.ReadString('\n')
-
-	if err != nil || err2 != nil || err3 != nil {
+	if err != nil {
 		fmt.Printf("Could not read string \n")
 	}
 
-	combinedInputs := combineStrings(ID, DisputeID, Content)
-	combinedInputs = strings.ReplaceAll(combinedInputs, "\n", "")
+	ID = combineStrings(ID)
+	ID = strings.ReplaceAll(ID, "\n", "")
+	bodyText := postRequest(ID, methodName)
 
-	// Will send and get a response from the blockchain
-	bodyText := postRequest(combinedInputs, "RespondToDispute")
 	jsonString := string(bodyText)
 	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
 	jsonString = strings.TrimSuffix(jsonString, "}")
@@ -424,29 +1251,45 @@ func respondToDispute() {
 		return
 	}
 
-	ID = combineStrings(ID)
-	ID = strings.ReplaceAll(ID, "\n", "")
-	prettifyDispute(choseContract(ID))
+	println(message)
+	prettifyTopContract(choseContract(ID))
 }
 
-func closeDispute() {
+func respondToDispute() {
+	// Taking all the need inputs from the user
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("Enter Contract ID: ")
 	ID, err := reader.ReadString('\n')
 	fmt.Print("Enter Dispute ID: ")
 	DisputeID, err2 := reader.ReadString('\n')
-
 	if err != nil || err2 != nil {
 		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+	DisputeID = strings.TrimSpace(DisputeID)
+
+	// The response is made against the revision the user is looking at.
+	revision, ok := disputeRevision(ID, DisputeID)
+	if !ok {
+		return
 	}
 
-	combinedInputs := combineStrings(ID, DisputeID)
-	combinedInputs = strings.ReplaceAll(combinedInputs, "\n", "")
+	fmt.Print("Enter the content of your response: ")
+	Content, err3 := reader.ReadString('\n')
+	if err3 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	Content = strings.ReplaceAll(Content, "\n", "")
 
 	// Will send and get a response from the blockchain
-	bodyText := postRequest(combinedInputs, "CloseDispute")
-	jsonString := string(bodyText)
-	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
+	bodyText := submitRevision(revision, func(revision int) string {
+		return postRequest(combineStrings(ID, DisputeID, Content, strconv.Itoa(revision)), "RespondToDispute")
+	}, func() (int, bool) {
+		return disputeRevision(ID, DisputeID)
+	})
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
 	jsonString = strings.TrimSuffix(jsonString, "}")
 
 	if isError(jsonString) {
@@ -454,50 +1297,394 @@ func closeDispute() {
 		return
 	}
 
-	ID = combineStrings(ID)
-	ID = strings.ReplaceAll(ID, "\n", "")
-	prettifyDispute(choseContract(ID))
+	prettifyDispute(choseContract(combineStrings(ID)))
 }
 
-func issueDispute() {
-	// Taking all the need inputs from the user
+func closeDispute() {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("Enter Contract ID: ")
 	ID, err := reader.ReadString('\n')
-	// fmt.Print("Enter Dispute ID: ")
-	// DisputeID, err2 := reader.ReadString('\n')
-
-	fmt.Print("Enter the content of your dispute: ")
-	Content1, err3 := reader.ReadString('\n')
+	fmt.Print("Enter Dispute ID: ")
+	DisputeID, err2 := reader.ReadString('\n')
 
-	if err != nil || err3 != nil {
+	if err != nil || err2 != nil {
 		fmt.Printf("Could not read string \n")
+		return
 	}
+	ID = strings.TrimSpace(ID)
+	DisputeID = strings.TrimSpace(DisputeID)
 
-	combinedInputs := combineStrings(ID, Content1)
-	combinedInputs = strings.ReplaceAll(combinedInputs, "\n", "")
+	revision, ok := disputeRevision(ID, DisputeID)
+	if !ok {
+		return
+	}
 
 	// Will send and get a response from the blockchain
-	bodyText := postRequest(combinedInputs, "IssueDispute")
-	jsonString := string(bodyText)
-	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
+	bodyText := submitRevision(revision, func(revision int) string {
+		return postRequest(combineStrings(ID, DisputeID, strconv.Itoa(revision)), "CloseDispute")
+	}, func() (int, bool) {
+		return disputeRevision(ID, DisputeID)
+	})
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
 	jsonString = strings.TrimSuffix(jsonString, "}")
 
 	if isError(jsonString) {
 		printError(jsonString)
 		return
 	}
-	ID = strings.ReplaceAll(ID, "\n", "")
-	ID = combineStrings(ID)
-	prettifyDispute(choseContract(ID))
 
+	prettifyDispute(choseContract(combineStrings(ID)))
+}
+
+func issueDispute() {
+	// Taking all the need inputs from the user
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+
+	// The dispute is raised against the revision the user is looking at.
+	revision, ok := contractRevision(ID)
+	if !ok {
+		return
+	}
+
+	fmt.Printf("Enter the category (%s): ", strings.Join(disputeCategories, ", "))
+	Category, err1 := reader.ReadString('\n')
+	fmt.Printf("Enter the severity (%s): ", strings.Join(disputeSeverities, ", "))
+	Severity, err2 := reader.ReadString('\n')
+
+	fmt.Print("Enter the content of your dispute: ")
+	Content1, err3 := reader.ReadString('\n')
+
+	if err1 != nil || err2 != nil || err3 != nil {
+		fmt.Printf("Could not read string \n")
+	}
+
+	Category = strings.TrimSpace(Category)
+	Severity = strings.TrimSpace(Severity)
+	Content1 = strings.ReplaceAll(Content1, "\n", "")
+
+	// Will send and get a response from the blockchain
+	bodyText := submitRevision(revision, func(revision int) string {
+		return postRequest(combineStrings(ID, Category, Severity, Content1, strconv.Itoa(revision)), "IssueDispute")
+	}, func() (int, bool) {
+		return contractRevision(ID)
+	})
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+
+	if isError(jsonString) {
+		printError(jsonString)
+		return
+	}
+	prettifyDispute(choseContract(combineStrings(ID)))
+
+}
+
+// Will ask for a contract ID, a dispute ID, and one input per prompt, and send them to the given dispute transaction.
+// Used by the arbitration transactions.
+func disputeTransaction(methodName string, message string, prompts ...string) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	fmt.Print("Enter Dispute ID: ")
+	DisputeID, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	inputs := []string{strings.TrimSpace(ID), strings.TrimSpace(DisputeID)}
+	revision, ok := disputeRevision(inputs[0], inputs[1])
+	if !ok {
+		return
+	}
+	for _, prompt := range prompts {
+		fmt.Print(prompt)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Printf("Could not read string %s \n", err)
+			return
+		}
+		inputs = append(inputs, strings.TrimSpace(input))
+	}
+
+	bodyText := submitRevision(revision, func(revision int) string {
+		return postRequest(combineStrings(append(inputs, strconv.Itoa(revision))...), methodName)
+	}, func() (int, bool) {
+		return disputeRevision(inputs[0], inputs[1])
+	})
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+
+	if isError(jsonString) {
+		printError(jsonString)
+		return
+	}
+	fmt.Println(message)
+	prettifyDispute(choseContract(combineStrings(inputs[0])))
+}
+
+// Will print every version of a dispute, starting with the oldest one.
+func disputeHistory() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	fmt.Print("Enter Dispute ID: ")
+	DisputeID, err2 := reader.ReadString('\n')
+	if err != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+
+	bodyText := postRequest(combineStrings(ID, strings.TrimSpace(DisputeID)), "GetDisputeHistory")
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	versions := []DisputeVersion{}
+	if err := json.Unmarshal([]byte(jsonString), &versions); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return
+	}
+
+	for i, version := range versions {
+		fmt.Printf("Version %d | %s | Tx %s \n", i+1, version.Timestamp, version.TxID)
+		if version.IsDelete {
+			fmt.Println("The dispute was deleted.")
+		} else {
+			prettifyDispute(Contract{ID: ID, Disputes: []Dispute{*version.Dispute}})
+		}
+		fmt.Println()
+	}
+}
+
+// The values IssueDispute accepts. The category decides how many days the employer has to respond.
+var disputeCategories = []string{"wages", "housing", "leave", "safety", "termination", "other"}
+var disputeSeverities = []string{"low", "medium", "high", "critical"}
+
+// DisputePage: one page of disputes as returned by GetOverdueDisputes.
+type DisputePage struct {
+	Records             []Dispute `json:"Records"`
+	FetchedRecordsCount int       `json:"Fetched records count"` // The number of Records.
+	ScannedRecordsCount int       `json:"Scanned records count"` // The number of disputes the page read, including the ones dropped.
+	Bookmark            string    `json:"Bookmark"`
+}
+
+// Will print the Active disputes the employer did not respond to before their due date. They are read a page at a time.
+func overdueDisputes() {
+	disputes := []Dispute{}
+	bookmark := ""
+	for {
+		bodyText := postRequest(combineStrings(strconv.Itoa(defaultPageSize), bookmark), "GetOverdueDisputes")
+		jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+		jsonString = strings.TrimSuffix(jsonString, "}")
+
+		if isError(jsonString) {
+			printError(jsonString)
+			return
+		}
+
+		page := DisputePage{}
+		if err := json.Unmarshal([]byte(jsonString), &page); err != nil {
+			fmt.Printf("Could not read the response: %s \n", jsonString)
+			return
+		}
+		disputes = append(disputes, page.Records...)
+		// A page that read fewer disputes than the page size is the last one.
+		if page.ScannedRecordsCount < defaultPageSize || page.Bookmark == "" {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+	if len(disputes) == 0 {
+		fmt.Println("There are no overdue disputes.")
+		return
+	}
+	prettifyDispute(Contract{ID: "Overdue", Disputes: disputes})
+}
+
+// Will ask for a contract ID and a status, and print the matching disputes of the contract.
+func viewDisputes() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	fmt.Print("Enter the status (Active, Escalated, Ruled, Closed, or leave empty for all): ")
+	status, err2 := reader.ReadString('\n')
+
+	if err != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+
+	ID = strings.TrimSpace(ID)
+	status = strings.TrimSpace(status)
+
+	bodyText := postRequest(combineStrings(ID, status), "GetDisputes")
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+
+	if isError(jsonString) {
+		printError(jsonString)
+		return
+	}
+
+	disputes := []Dispute{}
+	if err := json.Unmarshal([]byte(jsonString), &disputes); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return
+	}
+	if len(disputes) == 0 {
+		fmt.Println("No dispute matches the given status.")
+		return
+	}
+	prettifyDispute(Contract{ID: ID, Disputes: disputes})
+}
+
+// The number of contracts CompleteExpiredContracts reads, and ApplyDueAmendments changes, per transaction.
+const defaultSweepBatch = 50
+
+// Will complete the expired contracts and apply the due amendments, and print every changed ID.
+// Returns false if one of the batches failed.
+func sweepContracts(batchSize int) bool {
+	return completeExpiredContracts(batchSize) &&
+		runBatches("ApplyDueAmendments", batchSize, "Amended contract %s \n", "%d due amendments have been applied. \n")
+}
+
+// SweepBatch: what one call of CompleteExpiredContracts did.
+type SweepBatch struct {
+	Completed []string `json:"Completed"`
+	Scanned   int      `json:"Scanned"`
+	Bookmark  string   `json:"Bookmark"`
+}
+
+// Will call CompleteExpiredContracts from the bookmark each batch returns, until the last batch returns no bookmark.
+// Returns false if one of the batches failed.
+func completeExpiredContracts(batchSize int) bool {
+	total, scanned := 0, 0
+	bookmark := ""
+	for {
+		bodyText := postRequest(combineStrings(strconv.Itoa(batchSize), bookmark), "CompleteExpiredContracts")
+		if isError(bodyText) {
+			printError(bodyText)
+			fmt.Println()
+			return false
+		}
+
+		jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+		jsonString = strings.TrimSuffix(jsonString, "}")
+		var batch SweepBatch
+		if err := json.Unmarshal([]byte(jsonString), &batch); err != nil {
+			fmt.Printf("Could not read the response: %s \n", jsonString)
+			return false
+		}
+		for _, ID := range batch.Completed {
+			fmt.Printf("Completed contract %s \n", ID)
+		}
+		total += len(batch.Completed)
+		scanned += batch.Scanned
+		if batch.Bookmark == "" {
+			break
+		}
+		bookmark = batch.Bookmark
+	}
+	fmt.Printf("%d expired contracts out of %d have been completed. \n", total, scanned)
+	return true
+}
+
+// Will call the given batch transaction until it returns no IDs. lineFormat prints one ID, and totalFormat prints the count.
+func runBatches(methodName string, batchSize int, lineFormat string, totalFormat string) bool {
+	total := 0
+	for {
+		bodyText := postRequest(combineStrings(strconv.Itoa(batchSize)), methodName)
+		if isError(bodyText) {
+			printError(bodyText)
+			fmt.Println()
+			return false
+		}
+
+		jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+		jsonString = strings.TrimSuffix(jsonString, "}")
+		completed := []string{}
+		if err := json.Unmarshal([]byte(jsonString), &completed); err != nil {
+			fmt.Printf("Could not read the response: %s \n", jsonString)
+			return false
+		}
+		if len(completed) == 0 {
+			break
+		}
+		for _, ID := range completed {
+			fmt.Printf(lineFormat, ID)
+		}
+		total += len(completed)
+	}
+	fmt.Printf(totalFormat, total)
+	return true
+}
+
+// Will call ReindexContracts once, to build the employer and employee indexes of contracts created before they existed.
+// Returns false if the transaction failed.
+func reindexContracts() bool {
+	bodyText := postRequest("", "ReindexContracts")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return false
+	}
+
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	fmt.Printf("%s contracts have been indexed. \n", jsonString)
+	return true
+}
+
+// The transient map key the chaincode reads the pseudonym salt from.
+const saltTransientKey = "pseudonym_salt"
+
+// Will set a random salt for employee pseudonyms if none is set yet, then call PseudonymizeEmployees once to move the employees
+// written before pseudonyms existed to theirs. Returns false if a transaction failed.
+func pseudonymizeEmployees() bool {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		fmt.Printf("Could not create the salt: %s \n", err)
+		return false
+	}
+	bodyText := postRequestWithTransient("", "SetPseudonymSalt", map[string]string{saltTransientKey: hex.EncodeToString(salt)})
+	if isError(bodyText) && !strings.Contains(bodyText, "already set") {
+		printError(bodyText)
+		fmt.Println()
+		return false
+	}
+
+	bodyText = postRequest("", "PseudonymizeEmployees")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return false
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	fmt.Printf("%s contracts have been pseudonymized. \n", jsonString)
+	return true
 }
 
 // Get will return a token without any spaces.
 func getToken() string {
 	client := &http.Client{}
-	var data = strings.NewReader(`{"id": "admin", "secret": "adminpw"}`)
-	req, err := http.NewRequest("POST", "http://localhost:8801/user/enroll", data)
+	var data = strings.NewReader(`{"id": "` + getEnv("FABLO_USER", "admin") + `", "secret": "` + getEnv("FABLO_SECRET", "adminpw") + `"}`)
+	req, err := http.NewRequest("POST", restURL()+"/user/enroll", data)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -507,174 +1694,1424 @@ func getToken() string {
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer resp.Body.Close()
-	bodyText, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatal(err)
+	defer resp.Body.Close()
+	bodyText, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	str := string(bodyText)
+
+	str = strings.TrimPrefix(str, "{\"token\":\"")
+	str = strings.TrimSuffix(str, "\"}")
+
+	return str
+}
+
+type EmployeeData struct {
+	Contracts           string
+	TerminatedContracts string
+	ActiveContracts     string
+	PendingContracts    string
+	RejectedContracts   string
+	SuspendedContracts  string
+	CompletedContracts  string
+	Disputes            string
+	OpenDisputes        string
+	ClosedDisputes      string
+}
+
+func viewEmployeeHistory() {
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter EmployeeID: ")
+	choice, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	choice = strings.ReplaceAll(choice, "\n", "")
+	choice = combineStrings(choice)
+
+	// Will send and get a response from the blockchain
+	bodyText := postRequest(choice, "ViewEmployeeHistory")
+	if bodyText == "" {
+		return
+	}
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+
+	jsonString := string(bodyText)
+	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+
+	EmpData := EmployeeData{}
+	json.Unmarshal([]byte(jsonString), &EmpData)
+
+	if EmpData.Contracts == "0" {
+		fmt.Println("Invalid EmployeeID. Please try again.")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Contracts", "Terminated Contracts", "Active Contracts", "Pending Contracts", "Rejected Contracts", "Suspended Contracts", "Completed Contracts", "Disputes", "Open Disputes", "Closed Disputes"})
+	table.SetRowLine(true)
+
+	// Set the table style
+	table.SetBorder(true)
+	table.SetColumnSeparator("|")
+	table.SetCenterSeparator("+")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	// Append employee data.
+	row := []string{EmpData.Contracts, EmpData.TerminatedContracts, EmpData.ActiveContracts, EmpData.PendingContracts, EmpData.RejectedContracts, EmpData.SuspendedContracts, EmpData.CompletedContracts, EmpData.Disputes, EmpData.OpenDisputes, EmpData.ClosedDisputes}
+	table.Append(row)
+
+	table.Render()
+
+}
+
+func viewEmployerHistory() {
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter EmployerID: ")
+	choice, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	choice = strings.ReplaceAll(choice, "\n", "")
+	choice = combineStrings(choice)
+
+	// Will send and get a response from the blockchain
+	bodyText := postRequest(choice, "ViewEmployerHistory")
+	if bodyText == "" {
+		return
+	}
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	jsonString := string(bodyText)
+	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+
+	EmpData := EmployeeData{}
+	json.Unmarshal([]byte(jsonString), &EmpData)
+
+	if EmpData.Contracts == "0" {
+		fmt.Println("Invalid EmployerID. Please try again.")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Contracts", "Terminated Contracts", "Active Contracts", "Pending Contracts", "Rejected Contracts", "Suspended Contracts", "Completed Contracts", "Disputes", "Open Disputes", "Closed Disputes"})
+	table.SetRowLine(true)
+	// Set the table style
+	table.SetBorder(true)
+	table.SetColumnSeparator("|")
+	table.SetCenterSeparator("+")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	// Append employee data.
+	row := []string{EmpData.Contracts, EmpData.TerminatedContracts, EmpData.ActiveContracts, EmpData.PendingContracts, EmpData.RejectedContracts, EmpData.SuspendedContracts, EmpData.CompletedContracts, EmpData.Disputes, EmpData.OpenDisputes, EmpData.ClosedDisputes}
+	table.Append(row)
+
+	table.Render()
+
+}
+
+func updateContract() {
+	contract, ok := readContractFile()
+	if !ok {
+		return
+	}
+	jsonData, transient, err := splitPrivateDetails(contract)
+	if err != nil {
+		fmt.Printf("Could not prepare the contract: %s \n", err)
+		return
+	}
+
+	// The file carries the revision the contract was read at. The update is rejected if the contract changed since.
+	bodyText := submitRevision(contract.Revision, func(revision int) string {
+		return postRequestWithTransient(jsonData+","+combineStrings(strconv.Itoa(revision)), "UpdateContract", transient)
+	}, func() (int, bool) {
+		current := choseContract(combineStrings(contract.ID))
+		if current.ID == "" {
+			println("No matching ID in the blockchain. Please try again.")
+			return 0, false
+		}
+		changes := diffContracts(current, contract)
+		if len(changes) == 0 {
+			fmt.Println("Your file matches the current contract.")
+		} else {
+			fmt.Println("Your file will change the current contract as follows:")
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Field", "Current Value", "Your Value"})
+			table.AppendBulk(changes)
+			table.SetBorder(true)
+			table.SetColumnSeparator("|")
+			table.SetCenterSeparator("+")
+			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			table.Render()
+		}
+		return current.Revision, true
+	})
+
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println("The contract has been updated.")
+
+	prettifyTopContract(choseContract(combineStrings(contract.ID)))
+}
+
+// Will read the amended contract from a file and propose it to the other party.
+func proposeAmendment() {
+	contract, ok := readContractFile()
+	if !ok {
+		return
+	}
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter the effective date (MM/DD/YYYY): ")
+	effectiveDate, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	jsonData, transient, err := splitPrivateDetails(contract)
+	if err != nil {
+		fmt.Printf("Could not prepare the contract: %s \n", err)
+		return
+	}
+
+	bodyText := postRequestWithTransient(jsonData+","+combineStrings(strings.TrimSpace(effectiveDate)), "ProposeAmendment", transient)
+
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println("The amendment has been proposed. It will only apply once the other party accepts it.")
+	showPendingAmendments(contract.ID)
+}
+
+// Will ask for a contract ID and show its pending amendments.
+func pendingAmendments() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	showPendingAmendments(strings.TrimSpace(ID))
+}
+
+// Will print every Pending and Accepted amendment of the contract, with the fields it changes.
+func showPendingAmendments(ID string) {
+	current := choseContract(combineStrings(ID))
+	if current.ID == "" {
+		println("No matching ID in the blockchain. Please try again.")
+		return
+	}
+
+	amendments := []Amendment{}
+	for _, status := range []string{"Pending", "Accepted"} {
+		bodyText := postRequest(combineStrings(ID, status), "GetAmendments")
+		if isError(bodyText) {
+			printError(bodyText)
+			return
+		}
+		jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+		jsonString = strings.TrimSuffix(jsonString, "}")
+		page := []Amendment{}
+		if err := json.Unmarshal([]byte(jsonString), &page); err != nil {
+			fmt.Printf("Could not read the response: %s \n", jsonString)
+			return
+		}
+		amendments = append(amendments, page...)
+	}
+	if len(amendments) == 0 {
+		fmt.Println("The contract has no pending amendments.")
+		return
+	}
+
+	for _, amendment := range amendments {
+		fmt.Printf("Amendment %s | %s | Proposed by the %s on %s | Effective %s \n", amendment.ID, amendment.Status,
+			amendment.ProposedBy, amendment.ProposedDate, amendment.EffectiveDate)
+
+		proposed := current
+		proposed.Notes = amendment.Terms.Notes
+		proposed.StartDate = amendment.Terms.StartDate
+		proposed.EndDate = amendment.Terms.EndDate
+		proposed.ExtensionDetails = amendment.Terms.ExtensionDetails
+		proposed.Employer = amendment.Terms.Employer
+		proposed.Employee = amendment.Terms.Employee
+		proposed.Job = amendment.Terms.Job
+		proposed.Benefits = amendment.Terms.Benefits
+
+		changes := diffContracts(current, proposed)
+		if len(changes) == 0 {
+			fmt.Println("No field has changed.")
+		} else {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Field", "Current Value", "Proposed Value"})
+			table.AppendBulk(changes)
+			table.SetBorder(true)
+			table.SetColumnSeparator("|")
+			table.SetCenterSeparator("+")
+			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			table.Render()
+		}
+		fmt.Println()
+	}
+}
+
+// Will ask for a contract ID and an amendment ID, and send them to AcceptAmendment or RejectAmendment.
+// An amendment is accepted against the revision of the contract the user is looking at.
+func decideAmendment(methodName string, message string) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	fmt.Print("Enter Amendment ID: ")
+	AmendmentID, err2 := reader.ReadString('\n')
+	if err != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+	AmendmentID = strings.TrimSpace(AmendmentID)
+
+	var bodyText string
+	if methodName == "AcceptAmendment" {
+		revision, ok := contractRevision(ID)
+		if !ok {
+			return
+		}
+		bodyText = submitRevision(revision, func(revision int) string {
+			return postRequest(combineStrings(ID, AmendmentID, strconv.Itoa(revision)), methodName)
+		}, func() (int, bool) {
+			return contractRevision(ID)
+		})
+	} else {
+		bodyText = postRequest(combineStrings(ID, AmendmentID), methodName)
+	}
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println(message)
+	prettifyTopContract(choseContract(combineStrings(ID)))
+}
+
+// Will show the pay of a contract year by year, with the annual increases applied.
+func salarySchedule() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+
+	bodyText := postRequest(combineStrings(strings.TrimSpace(ID)), "GetSalarySchedule")
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	schedule := SalarySchedule{}
+	if err := json.Unmarshal([]byte(jsonString), &schedule); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return
+	}
+
+	fmt.Printf("Annual increase: %s. \n", schedule.AnnualIncrease)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Year", "From", "To", "Months", "Monthly Salary", "Monthly Gross Pay", "Gross Pay"})
+	for _, year := range schedule.Years {
+		table.Append([]string{strconv.Itoa(year.Year), year.From, year.To, strconv.Itoa(year.Months),
+			year.MonthlySalary.String(), year.MonthlyGrossPay.String(), year.GrossPay.String()})
+	}
+	table.SetFooter([]string{"", "", "", "", "", "Total", schedule.TotalGrossPay.String()})
+	table.SetBorder(true)
+	table.SetColumnSeparator("|")
+	table.SetCenterSeparator("+")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+}
+
+// Will ask for the dates of a leave and send the request to the employer.
+func requestLeave() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	fmt.Print("Enter the first day of leave (MM/DD/YYYY): ")
+	From, err2 := reader.ReadString('\n')
+	fmt.Print("Enter the last day of leave (MM/DD/YYYY): ")
+	To, err3 := reader.ReadString('\n')
+	fmt.Print("Enter the reason: ")
+	Reason, err4 := reader.ReadString('\n')
+	if err != nil || err2 != nil || err3 != nil || err4 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+
+	bodyText := postRequest(combineStrings(ID, strings.TrimSpace(From), strings.TrimSpace(To), strings.TrimSpace(Reason)), "RequestLeave")
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println("The leave has been requested. The employer can now approve or deny it.")
+	showLeave(ID)
+}
+
+// Will ask for a contract ID, a leave request ID, and one input per prompt, and send them to ApproveLeave or DenyLeave.
+func decideLeave(methodName string, message string, prompts ...string) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	fmt.Print("Enter Leave Request ID: ")
+	LeaveID, err2 := reader.ReadString('\n')
+	if err != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+	inputs := []string{ID, strings.TrimSpace(LeaveID)}
+	for _, prompt := range prompts {
+		fmt.Print(prompt)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Printf("Could not read string %s \n", err)
+			return
+		}
+		inputs = append(inputs, strings.TrimSpace(input))
+	}
+
+	bodyText := postRequest(combineStrings(inputs...), methodName)
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println(message)
+	showLeave(ID)
+}
+
+// Will ask for a contract ID and show its leave balance and leave requests.
+func leaveBalance() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	showLeave(strings.TrimSpace(ID))
+}
+
+// Will print the leave balance of the contract and every leave request. Returns false if they could not be read.
+func showLeave(ID string) bool {
+	bodyText := postRequest(combineStrings(ID), "GetLeaveBalance")
+	if isError(bodyText) {
+		printError(bodyText)
+		return false
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	balance := LeaveBalance{}
+	if err := json.Unmarshal([]byte(jsonString), &balance); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return false
+	}
+	fmt.Printf("On %s: %d days a year, %d accrued, %d taken, %d requested, %d available. \n", balance.Date,
+		balance.DaysPerYear, balance.Accrued, balance.Taken, balance.Requested, balance.Available)
+
+	bodyText = postRequest(combineStrings(ID, ""), "GetLeaveRequests")
+	if isError(bodyText) {
+		printError(bodyText)
+		return false
+	}
+	jsonString = strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	requests := []LeaveRequest{}
+	if err := json.Unmarshal([]byte(jsonString), &requests); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return false
+	}
+	if len(requests) == 0 {
+		fmt.Println("No leave has been requested on the contract.")
+		return true
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Request ID", "Status", "From", "To", "Days", "Reason", "Decided", "Denial Reason", "Dispute ID"})
+	for _, request := range requests {
+		table.Append([]string{request.ID, request.Status, request.From, request.To, strconv.Itoa(request.Days),
+			request.Reason, request.DecisionDate, request.DenialReason, request.DisputeID})
+	}
+	table.SetBorder(true)
+	table.SetColumnSeparator("|")
+	table.SetCenterSeparator("+")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+	return true
+}
+
+// Will raise a leave dispute about a Denied or unanswered leave request.
+func disputeLeave() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	fmt.Print("Enter Leave Request ID: ")
+	LeaveID, err2 := reader.ReadString('\n')
+	if err != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+
+	fmt.Printf("Enter the severity (%s): ", strings.Join(disputeSeverities, ", "))
+	Severity, err1 := reader.ReadString('\n')
+	fmt.Print("Enter the content of your dispute: ")
+	Content, err2 := reader.ReadString('\n')
+	if err1 != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+
+	inputs := combineStrings(ID, strings.TrimSpace(LeaveID), strings.TrimSpace(Severity), strings.TrimSpace(Content))
+	bodyText := postRequest(inputs, "DisputeLeave")
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println("The leave dispute has been issued.")
+	prettifyDispute(choseContract(combineStrings(ID)))
+}
+
+// Will read a rule pack from a file and publish it as the next version of the country's rules.
+// The CLI must be enrolled as a regulator of the country's MSP.
+func setRulePack() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Please enter the rule pack file name: ")
+	fileName, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	input, err := ioutil.ReadFile(strings.TrimSpace(fileName))
+	if err != nil {
+		fmt.Println("Failed to located the file. Please don't forget to add .json at the end.")
+		return
+	}
+	pack := RulePack{}
+	if err := json.Unmarshal(input, &pack); err != nil {
+		fmt.Printf("The file is not a valid rule pack: %s \n", err)
+		return
+	}
+	packJSON, err := json.Marshal(pack)
+	if err != nil {
+		fmt.Printf("Could not prepare the rule pack: %s \n", err)
+		return
+	}
+
+	// The new version replaces the one the regulator is looking at.
+	version, ok := rulePackVersion(pack.Country)
+	if !ok {
+		return
+	}
+	inputs := combineStrings(strings.ReplaceAll(string(packJSON), "\"", "'"))
+	bodyText := submitRevision(version, func(version int) string {
+		return postRequest(inputs+","+combineStrings(strconv.Itoa(version)), "SetRulePack")
+	}, func() (int, bool) {
+		return rulePackVersion(pack.Country)
+	})
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Printf("A new version of the rule pack of %s has been published. \n", pack.Country)
+	showRulePack(pack.Country, 0)
+}
+
+// Will read the latest rule pack of the country, show it, and return its version.
+func rulePackVersion(country string) (int, bool) {
+	pack, ok := showRulePack(country, 0)
+	return pack.Version, ok
+}
+
+// Will ask for a country and a version and show that version of the country's rules.
+func viewRulePack() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter the country: ")
+	country, err1 := reader.ReadString('\n')
+	fmt.Print("Enter the version, or leave it empty for the latest one: ")
+	versionText, err2 := reader.ReadString('\n')
+	if err1 != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	version := 0
+	if versionText = strings.TrimSpace(versionText); versionText != "" {
+		if version, err1 = strconv.Atoi(versionText); err1 != nil || version < 1 {
+			fmt.Println("Invalid version. Please enter a positive number.")
+			return
+		}
+	}
+	showRulePack(strings.TrimSpace(country), version)
+}
+
+// Will print a version of the country's rule pack, or the latest one if the version is 0. Returns false if it could not be read.
+func showRulePack(country string, version int) (RulePack, bool) {
+	pack := RulePack{}
+	bodyText := postRequest(combineStrings(country, strconv.Itoa(version)), "GetRulePack")
+	if isError(bodyText) {
+		printError(bodyText)
+		return pack, false
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	if err := json.Unmarshal([]byte(jsonString), &pack); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return pack, false
+	}
+	if pack.Version == 0 {
+		fmt.Printf("%s has no rule pack yet. \n", country)
+		return pack, true
+	}
+
+	fmt.Printf("Rule pack of %s, version %d, published by %s on %s. \n", pack.Country, pack.Version, pack.UpdatedBy, pack.UpdatedDate)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Rule ID", "Rule"})
+	for _, minimum := range pack.MinimumSalaries {
+		level := minimum.Level
+		if level == "*" {
+			level = "every other level"
+		}
+		table.Append([]string{"min-salary", fmt.Sprintf("%s a month for %s", minimum.Minimum, level)})
+	}
+	if pack.MaxContractMonths > 0 {
+		table.Append([]string{"max-contract-length", fmt.Sprintf("%d months", pack.MaxContractMonths)})
+	}
+	if pack.MinimumHousing != nil {
+		table.Append([]string{"min-housing", fmt.Sprintf("%s a month, housing is mandatory", pack.MinimumHousing)})
+	}
+	if pack.MinimumLeaveDays > 0 {
+		table.Append([]string{"min-annual-leave", fmt.Sprintf("%d days a year", pack.MinimumLeaveDays)})
+	}
+	if pack.MaxProbationMonths > 0 {
+		table.Append([]string{"max-probation", fmt.Sprintf("%d months", pack.MaxProbationMonths)})
+	}
+	table.SetBorder(true)
+	table.SetColumnSeparator("|")
+	table.SetCenterSeparator("+")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+	return pack, true
+}
+
+// DocumentStore: where the CLI keeps the files it attaches to contracts. Only their hash and URI go on the ledger.
+// Files are addressed by their SHA-256, so the same file is stored once and a changed file can't take the place of the one attached.
+type DocumentStore interface {
+	Put(hash string, content []byte) (string, error) // Returns the URI the file can be fetched from.
+	Get(hash string) ([]byte, error)
+}
+
+// documentStores maps the scheme of DOCUMENT_STORE to the store that handles it. Another store only needs an entry here.
+var documentStores = map[string]func(location string) DocumentStore{
+	"file": func(location string) DocumentStore { return directoryStore{dir: location} },
+}
+
+// Will return the store set by DOCUMENT_STORE, for example file:///srv/documents. The documents directory is used by default.
+func documentStore() (DocumentStore, error) {
+	location := getEnv("DOCUMENT_STORE", "file://documents")
+	scheme, path, found := strings.Cut(location, "://")
+	newStore, ok := documentStores[scheme]
+	if !found || !ok {
+		return nil, fmt.Errorf("unknown document store %s", location)
+	}
+	return newStore(path), nil
+}
+
+// directoryStore: a DocumentStore that keeps every file in a local directory, named after its hash.
+type directoryStore struct {
+	dir string
+}
+
+func (d directoryStore) Put(hash string, content []byte) (string, error) {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(d.dir, hash)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return "", err
+		}
+	}
+	return "file://" + filepath.ToSlash(path), nil
+}
+
+func (d directoryStore) Get(hash string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(d.dir, hash))
+}
+
+// Will return the SHA-256 of the content as 64 hex digits.
+func hashDocument(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// Will hash a local file, keep it in the document store, and anchor its hash to the contract. Returns false if it failed.
+func attachDocument(ID string, docType string, fileName string) bool {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		fmt.Printf("Could not read the file: %s \n", err)
+		return false
+	}
+	store, err := documentStore()
+	if err != nil {
+		fmt.Printf("Could not open the document store: %s \n", err)
+		return false
+	}
+	hash := hashDocument(content)
+	uri, err := store.Put(hash, content)
+	if err != nil {
+		fmt.Printf("Could not store the file: %s \n", err)
+		return false
+	}
+
+	bodyText := postRequest(combineStrings(ID, docType, hash, uri), "AttachDocument")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return false
+	}
+	fmt.Printf("The file has been attached with the SHA-256 %s and stored at %s. \n", hash, uri)
+	return showDocuments(ID)
+}
+
+// Will hash a file again and check it against the hash the document was attached with. Returns false if it doesn't match.
+// @Param fileName is the local copy to check. If it is empty, the copy in the document store is checked.
+func verifyDocument(ID string, DocumentID string, fileName string) bool {
+	var content []byte
+	if fileName != "" {
+		var err error
+		if content, err = ioutil.ReadFile(fileName); err != nil {
+			fmt.Printf("Could not read the file: %s \n", err)
+			return false
+		}
+	} else {
+		var ok bool
+		if content, ok = storedDocument(ID, DocumentID); !ok {
+			return false
+		}
+	}
+
+	hash := hashDocument(content)
+	bodyText := postRequest(combineStrings(ID, DocumentID, hash), "VerifyDocument")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return false
+	}
+	if strings.TrimSpace(bodyText) != "{\"response\":true}" {
+		fmt.Printf("The file does NOT match document %s of contract %s. Its SHA-256 is %s. \n", DocumentID, ID, hash)
+		return false
+	}
+	fmt.Printf("The file matches document %s of contract %s. \n", DocumentID, ID)
+	return true
+}
+
+// Will read the copy of the document kept in the document store, found by the hash on the ledger. Returns false if it can't be read.
+func storedDocument(ID string, DocumentID string) ([]byte, bool) {
+	documents, ok := getDocuments(ID)
+	if !ok {
+		return nil, false
+	}
+	store, err := documentStore()
+	if err != nil {
+		fmt.Printf("Could not open the document store: %s \n", err)
+		return nil, false
+	}
+	for _, document := range documents {
+		if document.ID != DocumentID {
+			continue
+		}
+		content, err := store.Get(document.SHA256)
+		if err != nil {
+			fmt.Printf("Could not read the file from the document store: %s \n", err)
+			return nil, false
+		}
+		return content, true
+	}
+	fmt.Printf("The contract %s has no document %s. \n", ID, DocumentID)
+	return nil, false
+}
+
+// Will ask for a contract, a document type, and a file, and attach the file to the contract.
+func attachDocumentPrompt() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err1 := reader.ReadString('\n')
+	fmt.Print("Enter the document type (offer letter, passport, payslip, visa, other): ")
+	docType, err2 := reader.ReadString('\n')
+	fmt.Print("Enter the file name: ")
+	fileName, err3 := reader.ReadString('\n')
+	if err1 != nil || err2 != nil || err3 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	attachDocument(strings.TrimSpace(ID), strings.TrimSpace(docType), strings.TrimSpace(fileName))
+}
+
+// Will ask for a document and a file, and check the file against the ledger.
+func verifyDocumentPrompt() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err1 := reader.ReadString('\n')
+	fmt.Print("Enter Document ID: ")
+	DocumentID, err2 := reader.ReadString('\n')
+	fmt.Print("Enter the file name, or leave it empty to check the copy in the document store: ")
+	fileName, err3 := reader.ReadString('\n')
+	if err1 != nil || err2 != nil || err3 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	verifyDocument(strings.TrimSpace(ID), strings.TrimSpace(DocumentID), strings.TrimSpace(fileName))
+}
+
+// Will ask for a contract ID and show the documents attached to it.
+func viewDocuments() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	showDocuments(strings.TrimSpace(ID))
+}
+
+// Will return the documents attached to the contract. Returns false if they could not be read.
+func getDocuments(ID string) ([]Document, bool) {
+	documents := []Document{}
+	bodyText := postRequest(combineStrings(ID), "GetDocuments")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return documents, false
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	if err := json.Unmarshal([]byte(jsonString), &documents); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return documents, false
+	}
+	return documents, true
+}
+
+// Will print the documents attached to the contract. Returns false if they could not be read.
+func showDocuments(ID string) bool {
+	documents, ok := getDocuments(ID)
+	if !ok {
+		return false
+	}
+	if len(documents) == 0 {
+		fmt.Println("No document has been attached to the contract.")
+		return true
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Document ID", "Type", "SHA-256", "URI", "Attached By", "Attached"})
+	for _, document := range documents {
+		table.Append([]string{document.ID, document.Type, document.SHA256, document.URI,
+			document.AttachedBy.Role + " " + document.AttachedBy.MSPID, document.AttachedDate})
+	}
+	table.SetBorder(true)
+	table.SetColumnSeparator("|")
+	table.SetCenterSeparator("+")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+	return true
+}
+
+// The file the signing key is kept in. Set SIGNING_KEY to keep the keys of several identities apart.
+func signingKeyFile() string {
+	return getEnv("SIGNING_KEY", "signing-key.pem")
+}
+
+// Will generate a new signing key, save the private key in signingKeyFile, and register the public key for the enrolled identity.
+// The private key never leaves this machine. Returns false if it failed.
+// @Param algorithm is either ed25519 or ecdsa, which uses the P-256 curve.
+func generateSigningKey(algorithm string) bool {
+	var privateKey interface{}
+	var publicKey interface{}
+	switch strings.ToLower(algorithm) {
+	case "ed25519":
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			fmt.Printf("Could not generate the key: %s \n", err)
+			return false
+		}
+		privateKey, publicKey = private, public
+	case "ecdsa":
+		private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			fmt.Printf("Could not generate the key: %s \n", err)
+			return false
+		}
+		privateKey, publicKey = private, &private.PublicKey
+	default:
+		fmt.Printf("Unknown algorithm %s. It can only be ed25519 or ecdsa. \n", algorithm)
+		return false
+	}
+
+	fileName := signingKeyFile()
+	if _, err := os.Stat(fileName); err == nil {
+		fmt.Printf("%s already exists. Move it away first, or set SIGNING_KEY to another file. \n", fileName)
+		return false
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		fmt.Printf("Could not encode the private key: %s \n", err)
+		return false
+	}
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	if err := ioutil.WriteFile(fileName, privatePEM, 0600); err != nil {
+		fmt.Printf("Could not save the private key: %s \n", err)
+		return false
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		fmt.Printf("Could not encode the public key: %s \n", err)
+		return false
+	}
+	fmt.Printf("The private key has been saved in %s. \n", fileName)
+
+	bodyText := postRequest(combineStrings(base64.StdEncoding.EncodeToString(publicDER)), "RegisterSigningKey")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return false
+	}
+	fmt.Println("The public key has been registered. Contracts you sign from now on are checked against it.")
+	return true
+}
+
+// Will read the private key saved by generateSigningKey.
+func readSigningKey() (interface{}, error) {
+	privatePEM, err := ioutil.ReadFile(signingKeyFile())
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", signingKeyFile())
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// Will show the terms of the contract, sign their hash with the saved key, and record the signature. Returns false if it failed.
+// The hash is computed again from the terms, so the signature covers what was shown and not only what the chaincode claims.
+func signContract(ID string) bool {
+	bodyText := postRequest(combineStrings(ID), "GetContractTerms")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return false
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	terms := SigningTerms{}
+	if err := json.Unmarshal([]byte(jsonString), &terms); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return false
+	}
+	digest := sha256.Sum256([]byte(terms.Terms))
+	if hex.EncodeToString(digest[:]) != terms.SHA256 {
+		fmt.Println("The hash returned by the chaincode does not match the terms. Nothing has been signed.")
+		return false
+	}
+	// The signature is only valid for these terms, so it is not reapplied if the contract changes in the meantime.
+	contract := choseContract(combineStrings(ID))
+	prettifyContract(contract)
+	fmt.Printf("The terms have the SHA-256 %s. \n", terms.SHA256)
+
+	privateKey, err := readSigningKey()
+	if err != nil {
+		fmt.Printf("Could not read the signing key: %s. Generate one first. \n", err)
+		return false
+	}
+	var signature []byte
+	switch key := privateKey.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, digest[:])
+	case *ecdsa.PrivateKey:
+		if signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:]); err != nil {
+			fmt.Printf("Could not sign: %s \n", err)
+			return false
+		}
+	default:
+		fmt.Printf("Unsupported signing key type %T. \n", privateKey)
+		return false
+	}
+
+	bodyText = postRequest(combineStrings(ID, base64.StdEncoding.EncodeToString(signature), strconv.Itoa(contract.Revision)), "SignContract")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return false
+	}
+	fmt.Println("Your signature has been recorded. The contract can be approved once both parties signed these terms.")
+	return true
+}
+
+// Will ask for the algorithm of the key and generate it.
+func generateSigningKeyPrompt() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter the algorithm (ed25519, ecdsa), or leave it empty for ed25519: ")
+	algorithm, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	if algorithm = strings.TrimSpace(algorithm); algorithm == "" {
+		algorithm = "ed25519"
+	}
+	generateSigningKey(algorithm)
+}
+
+// Will ask for a contract ID and sign its terms.
+func signContractPrompt() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	signContract(strings.TrimSpace(ID))
+}
+
+// Will ask whether the party is an employer or an employee, and for its ID. Returns false if the input could not be read.
+func readPartyID(reader *bufio.Reader) (string, string, bool) {
+	fmt.Print("Enter the role of the party (employer, employee): ")
+	role, err1 := reader.ReadString('\n')
+	fmt.Print("Enter the party ID: ")
+	ID, err2 := reader.ReadString('\n')
+	if err1 != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return "", "", false
+	}
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role != "employer" && role != "employee" {
+		fmt.Println("Invalid role. Please enter employer or employee.")
+		return "", "", false
+	}
+	return role, strings.TrimSpace(ID), true
+}
+
+// Will add an employer or an employee to the registry. It stays Pending until an authority of its country verifies it.
+func registerParty() {
+	reader := bufio.NewReader(os.Stdin)
+	role, ID, ok := readPartyID(reader)
+	if !ok {
+		return
+	}
+	fmt.Print("Enter the name: ")
+	name, err1 := reader.ReadString('\n')
+	fmt.Print("Enter the country: ")
+	country, err2 := reader.ReadString('\n')
+	if err1 != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+
+	bodyText := postRequest(combineStrings(ID, strings.TrimSpace(name), strings.TrimSpace(country)), "RegisterEmployer")
+	if role == "employee" {
+		// The employee ID and name are sent in the transient map, so they are not written to the block.
+		transient, err := identityTransient(ID, strings.TrimSpace(name))
+		if err != nil {
+			fmt.Printf("Could not read the employee identity: %s \n", err)
+			return
+		}
+		bodyText = postRequestWithTransient(combineStrings(strings.TrimSpace(country)), "RegisterEmployee", transient)
+	}
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println("The party has been registered. It can be named in contracts once an authority of its country verifies it.")
+	showParty(role, ID)
+}
+
+// Will change the name or the country of a registered party. It goes back to Pending until it is verified again.
+func updateParty() {
+	reader := bufio.NewReader(os.Stdin)
+	role, ID, ok := readPartyID(reader)
+	if !ok {
+		return
+	}
+	// The change is made against the revision of the party the user is looking at.
+	party, ok := showParty(role, ID)
+	if !ok {
+		return
+	}
+	fmt.Print("Enter the new name: ")
+	name, err1 := reader.ReadString('\n')
+	fmt.Print("Enter the new country: ")
+	country, err2 := reader.ReadString('\n')
+	if err1 != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+
+	// Employees are sent by their pseudonym, and their new name only in the transient map.
+	var transient map[string]string
+	name = strings.TrimSpace(name)
+	if role == "employee" {
+		var err error
+		if transient, err = identityTransient(ID, name); err != nil {
+			fmt.Printf("Could not read the employee identity: %s \n", err)
+			return
+		}
+		name = ""
+	}
+	inputs := combineStrings(role, party.ID, name, strings.TrimSpace(country))
+	bodyText := submitRevision(party.Revision, func(revision int) string {
+		return postRequestWithTransient(inputs+","+combineStrings(strconv.Itoa(revision)), "UpdateParty", transient)
+	}, func() (int, bool) {
+		return partyRevision(role, ID)
+	})
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println("The party has been updated, and must be verified again.")
+	showParty(role, ID)
+}
+
+// Will verify or reject a registered party, as an authority of its country.
+func setPartyStatus() {
+	reader := bufio.NewReader(os.Stdin)
+	role, ID, ok := readPartyID(reader)
+	if !ok {
+		return
+	}
+	party, ok := showParty(role, ID)
+	if !ok {
+		return
+	}
+	fmt.Print("Enter the status (Verified, Rejected): ")
+	status, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+
+	// Employees are sent by their pseudonym, since the arguments are written to the block.
+	inputs := combineStrings(role, party.ID, strings.TrimSpace(status))
+	bodyText := submitRevision(party.Revision, func(revision int) string {
+		return postRequest(inputs+","+combineStrings(strconv.Itoa(revision)), "SetPartyStatus")
+	}, func() (int, bool) {
+		return partyRevision(role, ID)
+	})
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println("The status of the party has been changed.")
+	showParty(role, ID)
+}
+
+// Will ask for a party and show it.
+func viewParty() {
+	reader := bufio.NewReader(os.Stdin)
+	role, ID, ok := readPartyID(reader)
+	if !ok {
+		return
+	}
+	showParty(role, ID)
+}
+
+// The transient map key the chaincode reads the cleartext employee ID and name from.
+const identityTransientKey = "employee_identity"
+
+// Will return the transient map that carries the cleartext ID and name of an employee.
+func identityTransient(ID string, name string) (map[string]string, error) {
+	identityJSON, err := json.Marshal(map[string]string{"ID": ID, "Name": name})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{identityTransientKey: string(identityJSON)}, nil
+}
+
+// Will read a party, show it, and return its current revision.
+func partyRevision(role string, ID string) (int, bool) {
+	party, ok := showParty(role, ID)
+	return party.Revision, ok
+}
+
+// Will print a registered party. Returns false if it could not be read.
+func showParty(role string, ID string) (Party, bool) {
+	party := Party{}
+	bodyText := postRequest(combineStrings(role, ID), "GetParty")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return party, false
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	if err := json.Unmarshal([]byte(jsonString), &party); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return party, false
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Field", "Value"})
+	table.Append([]string{"Role", party.Role})
+	table.Append([]string{"ID", party.ID})
+	table.Append([]string{"Name", party.Name})
+	table.Append([]string{"Country", party.Country})
+	table.Append([]string{"Status", party.Status})
+	table.Append([]string{"Registered", party.RegisteredDate + " by " + party.RegisteredBy})
+	if party.StatusBy != "" {
+		table.Append([]string{"Status Set", party.StatusDate + " by " + party.StatusBy})
+	}
+	table.SetBorder(true)
+	table.SetColumnSeparator("|")
+	table.SetCenterSeparator("+")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.Render()
+	return party, true
+}
+
+// The transient map key the chaincode reads the payment amount from.
+const paymentTransientKey = "payment_private"
+
+// PaymentPrivateDetails: the amount of a payment. The chaincode keeps it in the private data collection.
+type PaymentPrivateDetails struct {
+	Amount Money  `json:"Amount"`
+	Salt   string `json:"Salt"`
+}
+
+// Will ask for the details of a salary payment and record it. The amount is sent in the transient map.
+func recordPayment() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	fmt.Print("Enter the month the payment is for (MM/YYYY): ")
+	Period, err2 := reader.ReadString('\n')
+	fmt.Print("Enter the currency: ")
+	Currency, err3 := reader.ReadString('\n')
+	fmt.Print("Enter the amount: ")
+	AmountText, err4 := reader.ReadString('\n')
+	fmt.Print("Enter the bank or transfer reference: ")
+	Reference, err5 := reader.ReadString('\n')
+	if err != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	Amount, err := parseMoney(AmountText, strings.ToUpper(strings.TrimSpace(Currency)))
+	if err != nil || Amount.MinorUnits <= 0 {
+		fmt.Println("Invalid amount. Please enter a positive amount, such as 5000 or 5000.50.")
+		return
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		fmt.Printf("Could not create the salt: %s \n", err)
+		return
+	}
+	privateJSON, err := json.Marshal(PaymentPrivateDetails{Amount: Amount, Salt: hex.EncodeToString(salt)})
+	if err != nil {
+		fmt.Printf("Could not prepare the payment: %s \n", err)
+		return
+	}
+
+	ID = strings.TrimSpace(ID)
+	inputs := combineStrings(ID, strings.TrimSpace(Period), strings.TrimSpace(Currency), strings.TrimSpace(Reference))
+	bodyText := postRequestWithTransient(inputs, "RecordSalaryPayment", map[string]string{paymentTransientKey: string(privateJSON)})
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println("The payment has been recorded. The employee can now confirm it.")
+	showPayments(ID)
+}
+
+// Will ask for a payment and confirm or reject it as the employee. Each prompt asks for one more argument of the transaction.
+func decidePayment(methodName string, message string, prompts ...string) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
+	fmt.Print("Enter Payment ID: ")
+	PaymentID, err2 := reader.ReadString('\n')
+	if err != nil || err2 != nil {
+		fmt.Printf("Could not read string \n")
+		return
+	}
+	ID = strings.TrimSpace(ID)
+	inputs := []string{ID, strings.TrimSpace(PaymentID)}
+	for _, prompt := range prompts {
+		fmt.Print(prompt)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Printf("Could not read string %s \n", err)
+			return
+		}
+		inputs = append(inputs, strings.TrimSpace(input))
 	}
-	str := string(bodyText)
-
-	str = strings.TrimPrefix(str, "{\"token\":\"")
-	str = strings.TrimSuffix(str, "\"}")
-
-	return str
-}
 
-type EmployeeData struct {
-	Contracts           string
-	TerminatedContracts string
-	ActiveContracts     string
-	PendingContracts    string
-	Disputes            string
-	OpenDisputes        string
-	ClosedDisputes      string
+	bodyText := postRequest(combineStrings(inputs...), methodName)
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	fmt.Println(message)
+	showPayments(ID)
 }
 
-func viewEmployeeHistory() {
-
+// Will show the payments of a contract, and the arrears month by month.
+func paymentsAndArrears() {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Enter EmployeeID: ")
-	choice, err := reader.ReadString('\n')
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
 	if err != nil {
 		fmt.Printf("Could not read string %s \n", err)
 		return
 	}
-	choice = strings.ReplaceAll(choice, "\n", "")
-	choice = combineStrings(choice)
-
-	// Will send and get a response from the blockchain
-	bodyText := postRequest(choice, "ViewEmployeeHistory")
-	if bodyText == "" {
+	ID = strings.TrimSpace(ID)
+	if !showPayments(ID) {
 		return
 	}
+	fmt.Println()
 
-	jsonString := string(bodyText)
-	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
+	bodyText := postRequest(combineStrings(ID), "GetArrears")
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
 	jsonString = strings.TrimSuffix(jsonString, "}")
-
-	EmpData := EmployeeData{}
-	json.Unmarshal([]byte(jsonString), &EmpData)
-
-	if EmpData.Contracts == "0" {
-		fmt.Println("Invalid EmployeeID. Please try again.")
+	report := ArrearsReport{}
+	if err := json.Unmarshal([]byte(jsonString), &report); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return
+	}
+	if len(report.Periods) == 0 {
+		fmt.Println("No month of the contract is due yet.")
 		return
 	}
 
+	fmt.Printf("Arrears in %s \n", report.Currency)
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Contracts", "Terminated Contracts", "Active Contracts", "Pending Contracts", "Disputes", "Open Disputes", "Closed Disputes"})
-	table.SetRowLine(true)
-
-	// Set the table style
+	table.SetHeader([]string{"Month", "Expected", "Paid", "Confirmed", "Disputed", "Rejected", "Arrears"})
+	for _, period := range report.Periods {
+		table.Append([]string{period.Period, period.Expected.String(), period.Paid.String(),
+			period.Confirmed.String(), period.Disputed.String(), period.Rejected.String(), period.Arrears.String()})
+	}
+	table.SetFooter([]string{"Total", report.TotalExpected.String(), report.TotalPaid.String(),
+		report.TotalConfirmed.String(), report.TotalDisputed.String(), report.TotalRejected.String(), report.TotalArrears.String()})
 	table.SetBorder(true)
 	table.SetColumnSeparator("|")
 	table.SetCenterSeparator("+")
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
-
-	// Append employee data.
-	row := []string{EmpData.Contracts, EmpData.TerminatedContracts, EmpData.ActiveContracts, EmpData.PendingContracts, EmpData.Disputes, EmpData.OpenDisputes, EmpData.ClosedDisputes}
-	table.Append(row)
-
 	table.Render()
-
 }
 
-func viewEmployerHistory() {
-
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Enter EmployerID: ")
-	choice, err := reader.ReadString('\n')
-	if err != nil {
-		fmt.Printf("Could not read string %s \n", err)
-		return
-	}
-	choice = strings.ReplaceAll(choice, "\n", "")
-	choice = combineStrings(choice)
-
-	// Will send and get a response from the blockchain
-	bodyText := postRequest(choice, "ViewEmployerHistory")
-	if bodyText == "" {
-		return
+// Will print the payments of the contract. Returns false if they could not be read.
+func showPayments(ID string) bool {
+	bodyText := postRequest(combineStrings(ID), "GetPayments")
+	if isError(bodyText) {
+		printError(bodyText)
+		return false
 	}
-	jsonString := string(bodyText)
-	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
 	jsonString = strings.TrimSuffix(jsonString, "}")
-
-	EmpData := EmployeeData{}
-	json.Unmarshal([]byte(jsonString), &EmpData)
-
-	if EmpData.Contracts == "0" {
-		fmt.Println("Invalid EmployerID. Please try again.")
-		return
+	payments := []Payment{}
+	if err := json.Unmarshal([]byte(jsonString), &payments); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return false
+	}
+	if len(payments) == 0 {
+		fmt.Println("No payment has been recorded for the contract.")
+		return true
 	}
 
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Contracts", "Terminated Contracts", "Active Contracts", "Pending Contracts", "Disputes", "Open Disputes", "Closed Disputes"})
-	table.SetRowLine(true)
-	// Set the table style
+	table.SetHeader([]string{"Payment ID", "Month", "Amount", "Reference", "Recorded", "Confirmed"})
+	for _, payment := range payments {
+		amount := "Hidden"
+		if payment.Amount.MinorUnits != 0 {
+			amount = payment.Amount.String()
+		}
+		confirmed := "No"
+		if payment.Confirmed {
+			confirmed = payment.ConfirmedDate
+		}
+		if payment.Rejected {
+			confirmed = fmt.Sprintf("Rejected on %s: %s", payment.RejectedDate, payment.Reason)
+		}
+		table.Append([]string{payment.ID, payment.Period, amount, payment.Reference, payment.RecordedDate, confirmed})
+	}
 	table.SetBorder(true)
 	table.SetColumnSeparator("|")
 	table.SetCenterSeparator("+")
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
-
-	// Append employee data.
-	row := []string{EmpData.Contracts, EmpData.TerminatedContracts, EmpData.ActiveContracts, EmpData.PendingContracts, EmpData.Disputes, EmpData.OpenDisputes, EmpData.ClosedDisputes}
-	table.Append(row)
-
 	table.Render()
-
+	return true
 }
 
-func updateContract() {
-
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Please enter the contract file name: ")
-	choice, err := reader.ReadString('\n')
-	if err != nil {
-		fmt.Printf("Could not read string %s \n", err)
+func createContract() {
+	contract, ok := readContractFile()
+	if !ok {
 		return
 	}
-
-	choice = strings.ReplaceAll(choice, "\n", "")
-	// Read the contents of the file
-	input, err := ioutil.ReadFile(choice)
+	jsonData, transient, err := splitPrivateDetails(contract)
 	if err != nil {
-		fmt.Println("Failed to located the file. Please don't forget to add .json at the end.")
+		fmt.Printf("Could not prepare the contract: %s \n", err)
 		return
-
 	}
 
-	// Convert the byte slice to a string and remove newline characters
-	jsonData := string(input)
-	jsonData = strings.ReplaceAll(jsonData, "\n", "")
-	jsonData = strings.ReplaceAll(jsonData, "\"", "'")
-	jsonData = combineStrings(jsonData)
-
-	bodyText := postRequest(jsonData, "UpdateContract")
+	bodyText := postRequestWithTransient(jsonData, "HandleAddContract", transient)
 
 	if isError(bodyText) {
 		printError(bodyText)
 		return
 	}
-	fmt.Println("The contract has been updated.")
+	fmt.Println("A contract with the status Pending has been created.")
 
-	// We need to retrieve the id from the json string
-	_, jsonData, _ = strings.Cut(jsonData, ": ")
-	jsonData, _, _ = strings.Cut(jsonData, ",")
-	jsonData = strings.ReplaceAll(jsonData, "'", "")
-	jsonData = combineStrings(jsonData)
-	prettifyTopContract(choseContract(jsonData))
+	prettifyTopContract(choseContract(combineStrings(contract.ID)))
 }
 
-func createContract() {
+// Will ask for a contract file name and read the contract from it.
+func readContractFile() (Contract, bool) {
+	contract := Contract{}
 
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("Please enter the contract file name: ")
 	choice, err := reader.ReadString('\n')
 	if err != nil {
 		fmt.Printf("Could not read string %s \n", err)
-		return
+		return contract, false
 	}
 
 	choice = strings.ReplaceAll(choice, "\n", "")
@@ -682,30 +3119,73 @@ func createContract() {
 	input, err := ioutil.ReadFile(choice)
 	if err != nil {
 		fmt.Println("Failed to located the file. Please don't forget to add .json at the end.")
-		return
+		return contract, false
 
 	}
 
-	// Convert the byte slice to a string and remove newline characters
-	jsonData := string(input)
-	jsonData = strings.ReplaceAll(jsonData, "\n", "")
-	jsonData = strings.ReplaceAll(jsonData, "\"", "'")
-	jsonData = combineStrings(jsonData)
+	if err := json.Unmarshal(input, &contract); err != nil {
+		fmt.Printf("The file is not a valid contract: %s \n", err)
+		return contract, false
+	}
+	return contract, true
+}
 
-	bodyText := postRequest(jsonData, "HandleAddContract")
+// The transient map key the chaincode reads the private details from.
+const privateTransientKey = "contract_private"
 
-	if isError(bodyText) {
-		printError(bodyText)
-		return
+// ContractPrivateDetails: the fields the chaincode keeps in the private data collection instead of the shared world state.
+type ContractPrivateDetails struct {
+	Salary     Money  `json:"Salary"`
+	Housing    Money  `json:"Housing"`
+	Allowances Money  `json:"Allowances"`
+	EmployerAC string `json:"Employer address and contact details"`
+	EmployeeAC string `json:"Employee address and contact details"`
+	Salt       string `json:"Salt"`
+	// The cleartext employee ID and name. The ledger only knows the employee by a pseudonym.
+	EmployeeID   string `json:"Employee ID,omitempty"`
+	EmployeeName string `json:"Employee name,omitempty"`
+}
+
+// Will move the salary, the contact details, and the employee ID and name out of the contract and into the transient map.
+// Returns the contract formatted as the argument of HandleAddContract/UpdateContract, and the transient map.
+func splitPrivateDetails(contract Contract) (string, map[string]string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", nil, err
+	}
+	private := ContractPrivateDetails{
+		Salary:     contract.Benefits.Salary,
+		Housing:    contract.Benefits.Housing,
+		Allowances: contract.Benefits.Allowances,
+		EmployerAC: contract.Employer.EmployerAC,
+		EmployeeAC: contract.Employee.EmployeeAC,
+		Salt:       hex.EncodeToString(salt),
+		EmployeeID: contract.Employee.ID,
+		// The chaincode takes the name from the registry when it is left empty.
+		EmployeeName: contract.Employee.Name,
+	}
+	// A contract read from the ledger has the pseudonym as its ID, and the cleartext ID as its Identifier.
+	if contract.Employee.Identifier != "" {
+		private.EmployeeID = contract.Employee.Identifier
+	}
+	privateJSON, err := json.Marshal(private)
+	if err != nil {
+		return "", nil, err
+	}
+
+	contract.Benefits.Salary = Money{Currency: contract.Benefits.Currency}
+	contract.Benefits.Housing = Money{Currency: contract.Benefits.Currency}
+	contract.Benefits.Allowances = Money{Currency: contract.Benefits.Currency}
+	contract.Employer.EmployerAC = ""
+	contract.Employee.EmployeeAC = ""
+	contract.Employee.ID, contract.Employee.Name, contract.Employee.Identifier = "", "", ""
+	contractJSON, err := json.Marshal(contract)
+	if err != nil {
+		return "", nil, err
 	}
-	fmt.Println("A contract with the status Pending has been created.")
 
-	// We need to retrieve the id from the json string
-	_, jsonData, _ = strings.Cut(jsonData, ": ")
-	jsonData, _, _ = strings.Cut(jsonData, ",")
-	jsonData = strings.ReplaceAll(jsonData, "'", "")
-	jsonData = combineStrings(jsonData)
-	prettifyTopContract(choseContract(jsonData))
+	jsonData := strings.ReplaceAll(string(contractJSON), "\"", "'")
+	return combineStrings(jsonData), map[string]string{privateTransientKey: string(privateJSON)}, nil
 }
 
 // Will only show the contract top level info
@@ -759,25 +3239,278 @@ func readContract() Contract {
 
 }
 
-func getAllContracts() []Contract {
+// ContractPage: one page of contracts as returned by GetAllContractsWithPagination.
+type ContractPage struct {
+	Records             []Contract `json:"Records"`
+	FetchedRecordsCount int        `json:"Fetched records count"` // The number of Records.
+	ScannedRecordsCount int        `json:"Scanned records count"` // The number of records the page read, including the ones dropped.
+	Bookmark            string     `json:"Bookmark"`
+}
 
-	contracts := []Contract{}
+// The page size used when the user doesn't enter one.
+const defaultPageSize = 10
+
+// Will show all contracts one page at a time. The user can move to the next and previous pages.
+func browseAllContracts() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("Enter the page size (default %d): ", defaultPageSize)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	pageSize := defaultPageSize
+	if input = strings.TrimSpace(input); input != "" {
+		pageSize, err = strconv.Atoi(input)
+		if err != nil || pageSize < 1 {
+			fmt.Println("Invalid page size. Please enter a positive number.")
+			return
+		}
+	}
+
+	// bookmarks[i] is the bookmark that fetches page i. The first page has an empty bookmark.
+	bookmarks := []string{""}
+	pageNumber := 0
+	lastPage := -1 // Set once we know which page is the last one.
+	for {
+		page, ok := getContractsPage(pageSize, bookmarks[pageNumber])
+		if !ok {
+			return
+		}
+		if len(page.Records) == 0 && pageNumber == 0 {
+			fmt.Println("There are no contracts in the blockchain.")
+			return
+		}
+		// The previous page was full, but it was also the last one.
+		if len(page.Records) == 0 {
+			fmt.Println("There are no more contracts.")
+			bookmarks = bookmarks[:pageNumber]
+			pageNumber--
+			lastPage = pageNumber
+			continue
+		}
+
+		fmt.Printf("Page %d \n", pageNumber+1)
+		prettifyAllContracts(page.Records)
+
+		// A page that read fewer records than the page size is the last one.
+		hasNext := page.ScannedRecordsCount == pageSize && page.Bookmark != "" && pageNumber != lastPage
+		hasPrevious := pageNumber > 0
+		if !hasNext && !hasPrevious {
+			return
+		}
+
+		prompt := []string{}
+		if hasNext {
+			prompt = append(prompt, "[n]ext")
+		}
+		if hasPrevious {
+			prompt = append(prompt, "[p]revious")
+		}
+		prompt = append(prompt, "[q]uit")
+		fmt.Printf("%s: ", strings.Join(prompt, ", "))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "n":
+			if !hasNext {
+				fmt.Println("This is the last page.")
+				continue
+			}
+			if pageNumber+1 == len(bookmarks) {
+				bookmarks = append(bookmarks, page.Bookmark)
+			}
+			pageNumber++
+		case "p":
+			if !hasPrevious {
+				fmt.Println("This is the first page.")
+				continue
+			}
+			pageNumber--
+		default:
+			return
+		}
+	}
+}
+
+// Will fetch one page of contracts. Returns false if the request failed.
+func getContractsPage(pageSize int, bookmark string) (ContractPage, bool) {
+	page := ContractPage{}
 
 	// Will send and get a response from the blockchain
-	bodyText := postRequest("", "GetAllContracts")
+	bodyText := postRequest(combineStrings(strconv.Itoa(pageSize), bookmark), "GetAllContractsWithPagination")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
+		return page, false
+	}
 
-	jsonString := string(bodyText)
-	jsonString = strings.TrimPrefix(string(bodyText), "{\"response\":")
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
 	jsonString = strings.TrimSuffix(jsonString, "}")
-	err := json.Unmarshal([]byte(jsonString), &contracts)
+	if err := json.Unmarshal([]byte(jsonString), &page); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return page, false
+	}
+	return page, true
+}
 
+// Will print every version of a contract as a timeline, with the fields that changed since the previous version.
+func contractHistory() {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter Contract ID: ")
+	ID, err := reader.ReadString('\n')
 	if err != nil {
-		fmt.Print("Got this string: %s", jsonString)
-		fmt.Errorf("%s", err)
+		fmt.Printf("Could not read string %s \n", err)
+		return
+	}
+	ID = strings.ReplaceAll(ID, "\n", "")
+	ID = combineStrings(ID)
+
+	bodyText := postRequest(ID, "GetContractHistory")
+	if isError(bodyText) {
+		printError(bodyText)
+		return
+	}
+
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	versions := []ContractVersion{}
+	if err := json.Unmarshal([]byte(jsonString), &versions); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+		return
+	}
+
+	var previous *Contract
+	for i, version := range versions {
+		fmt.Printf("Version %d | %s | Tx %s \n", i+1, version.Timestamp, version.TxID)
+
+		if version.IsDelete {
+			fmt.Println("The contract was deleted.")
+			previous = nil
+			fmt.Println()
+			continue
+		}
+		if previous == nil {
+			fmt.Println("The contract was created.")
+			prettifyTopContract(*version.Contract)
+			previous = version.Contract
+			fmt.Println()
+			continue
+		}
+
+		changes := diffContracts(*previous, *version.Contract)
+		if len(changes) == 0 {
+			fmt.Println("No field has changed.")
+		} else {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Field", "Old Value", "New Value"})
+			table.AppendBulk(changes)
+			table.SetBorder(true)
+			table.SetColumnSeparator("|")
+			table.SetCenterSeparator("+")
+			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			table.Render()
+		}
+		previous = version.Contract
+		fmt.Println()
+	}
+}
+
+// Will return one row of field name, old value, and new value for every field that differs between the two contracts.
+func diffContracts(oldContract Contract, newContract Contract) [][]string {
+	oldFields := flattenContract(oldContract)
+	newFields := flattenContract(newContract)
+
+	names := []string{}
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := [][]string{}
+	for _, name := range names {
+		if oldFields[name] != newFields[name] {
+			changes = append(changes, []string{name, oldFields[name], newFields[name]})
+		}
+	}
+	return changes
+}
+
+// Will turn the contract into a map of field paths, such as "Employer > Name", to their values.
+func flattenContract(contract Contract) map[string]string {
+	fields := map[string]string{}
+	contractJSON, _ := json.Marshal(contract)
+	var tree interface{}
+	json.Unmarshal(contractJSON, &tree)
+	flattenValue("", tree, fields)
+	return fields
+}
+
+func flattenValue(path string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flattenValue(joinPath(path, key), child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenValue(joinPath(path, strconv.Itoa(i)), child, fields)
+		}
+	case nil:
+	default:
+		fields[path] = fmt.Sprint(v)
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + " > " + key
+}
+
+// Will run a CouchDB selector against the ledger and return the matching contracts. Needs the CouchDB network.
+func queryContracts() []Contract {
+	contracts := []Contract{}
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Println("Enter a CouchDB selector, for example {\"Status\":\"Active\",\"Employer.ID\":\"Comp-1\"}")
+	fmt.Print("Selector: ")
+	selector, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("Could not read string %s \n", err)
+		return contracts
+	}
+
+	selector = strings.TrimSpace(selector)
+	selector = strings.ReplaceAll(selector, "\"", "'")
+	selector = combineStrings(selector)
+
+	bodyText := postRequest(selector, "QueryContracts")
+	if isError(bodyText) {
+		printError(bodyText)
+		fmt.Println()
 		return contracts
 	}
-	return contracts
 
+	jsonString := strings.TrimPrefix(bodyText, "{\"response\":")
+	jsonString = strings.TrimSuffix(jsonString, "}")
+	if err := json.Unmarshal([]byte(jsonString), &contracts); err != nil {
+		fmt.Printf("Could not read the response: %s \n", jsonString)
+	}
+	if len(contracts) == 0 {
+		fmt.Println("No contract matches the selector.")
+	}
+	return contracts
 }
 
 // will format the strings into accepted format for Fablo rest api.
@@ -849,7 +3582,12 @@ func prettifyContract(contract Contract) {
 	table.Append([]string{"Employer Country", contract.Employer.Country})
 
 	// Append employee details
-	table.Append([]string{"Employee ID", contract.Employee.ID})
+	if contract.Employee.Identifier != "" {
+		table.Append([]string{"Employee ID", contract.Employee.Identifier})
+		table.Append([]string{"Employee Pseudonym", contract.Employee.ID})
+	} else {
+		table.Append([]string{"Employee ID", contract.Employee.ID})
+	}
 	table.Append([]string{"Employee Name", contract.Employee.Name})
 	table.Append([]string{"Employee Address and Contact", contract.Employee.EmployeeAC})
 	table.Append([]string{"Employee Country", contract.Employee.Country})
@@ -858,16 +3596,34 @@ func prettifyContract(contract Contract) {
 	table.Append([]string{"Position", contract.Job.Position})
 	table.Append([]string{"Level", contract.Job.Level})
 	table.Append([]string{"Description", contract.Job.Description})
+	if contract.Job.ProbationMonths > 0 {
+		table.Append([]string{"Probation", fmt.Sprintf("%d months", contract.Job.ProbationMonths)})
+	}
 
 	// Append benefits details
 	table.Append([]string{"Currency", contract.Benefits.Currency})
-	table.Append([]string{"Salary", strconv.Itoa(contract.Benefits.Salary)})
-	table.Append([]string{"Annual Increase", contract.Benefits.AnnualIncrease})
-	table.Append([]string{"Annual Leave", contract.Benefits.AnnualLeave})
-	table.Append([]string{"Housing", strconv.Itoa(contract.Benefits.Housing)})
-	table.Append([]string{"Allowances", strconv.Itoa(contract.Benefits.Allowances)})
+	table.Append([]string{"Salary", contract.Benefits.Salary.String()})
+	table.Append([]string{"Annual Increase", contract.Benefits.AnnualIncrease.String()})
+	table.Append([]string{"Annual Leave", contract.Benefits.AnnualLeave.String()})
+	table.Append([]string{"Housing", contract.Benefits.Housing.String()})
+	table.Append([]string{"Allowances", contract.Benefits.Allowances.String()})
 	table.Append([]string{"Other Benefits", contract.Benefits.OtherBenefits})
 
+	// Append approval details
+	for _, party := range contract.RequiredApprovals {
+		table.Append([]string{"Approval " + party, approvalState(contract, party)})
+	}
+
+	// Append the signatures over the terms
+	for _, signature := range contract.Signatures {
+		table.Append([]string{"Signed by " + signature.Party, "On " + signature.Date + ", terms " + signature.TermsHash})
+	}
+
+	// Append the rule packs the terms were checked against
+	for _, version := range contract.Compliance {
+		table.Append([]string{"Rule Pack " + version.Country, fmt.Sprintf("version %d", version.Version)})
+	}
+
 	// Append disputes details
 	for _, dispute := range contract.Disputes {
 		table.Append([]string{"Dispute ID", dispute.ID})
@@ -901,6 +3657,16 @@ func prettifyContract(contract Contract) {
 	table.Render()
 }
 
+// Will return the date the party approved the contract at, or Waiting if it did not approve yet.
+func approvalState(contract Contract, party string) string {
+	for _, approval := range contract.Approvals {
+		if approval.Party == party {
+			return "Approved on " + approval.Date
+		}
+	}
+	return "Waiting"
+}
+
 func prettifyTopContract(contract Contract) {
 	// In case the user enters a wrong ID
 	if contract.ID == "" {
@@ -920,7 +3686,7 @@ func prettifyTopContract(contract Contract) {
 	table.Append([]string{"Start Date", contract.StartDate})
 	table.Append([]string{"End Date", contract.EndDate})
 	table.Append([]string{"Extension Details", contract.ExtensionDetails})
-	table.Append([]string{"Salary", strconv.Itoa(contract.Benefits.Salary)})
+	table.Append([]string{"Salary", contract.Benefits.Salary.String()})
 
 	// Setting the colors of the columns
 	table.SetHeaderColor(
@@ -960,7 +3726,24 @@ func prettifyDispute(contract Contract) {
 		dispute := contract.Disputes[i]
 
 		table.Append([]string{"Dispute ID", dispute.ID})
+		table.Append([]string{"Contract ID", dispute.ContractID})
 		table.Append([]string{"Status", dispute.Status})
+		table.Append([]string{"Category", dispute.Category})
+		table.Append([]string{"Severity", dispute.Severity})
+		table.Append([]string{"Response Due", dispute.ResponseDueDate})
+		if dispute.LeaveRequestID != "" {
+			table.Append([]string{"Leave Request", dispute.LeaveRequestID})
+		}
+		if dispute.EscalatedDate != "" {
+			table.Append([]string{"Escalated", dispute.EscalatedDate})
+		}
+		if dispute.Ruling != nil {
+			table.Append([]string{"Ruling", dispute.Ruling.Decision})
+			table.Append([]string{"Ruled By", dispute.Ruling.Arbitrator + " on " + dispute.Ruling.Date})
+			if dispute.Ruling.Enforced {
+				table.Append([]string{"Enforced By", dispute.Ruling.EnforcedBy + " on " + dispute.Ruling.EnforcedDate})
+			}
+		}
 		table.Append([]string{"Last Updated", dispute.LastUpdatedDate})
 		table.Append([]string{"Content", dispute.Content})
 		for j := 0; j < len(dispute.Responses); j++ {
@@ -0,0 +1,643 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Amendments are stored as their own records under this composite key object type.
+// Their private details are kept in privateCollection under the same key.
+const amendmentKeyType = "contract~amendment" // contract ID, amendment ID
+
+// AmendmentTerms: the part of a contract an amendment can change. The employer and employee IDs can't be changed.
+type AmendmentTerms struct {
+	Notes            string `json:"Notes"`
+	StartDate        string `json:"Start date"`
+	EndDate          string `json:"End date"`
+	ExtensionDetails string `json:"Extension details"`
+	Employer         Employer
+	Employee         Employee
+	Job              Job
+	Benefits         Benefits
+}
+
+// Amendment: a change to the terms of an Active or Suspended contract. It is proposed by one party and must be accepted by the other.
+// Its status moves from Pending to Rejected, or to Accepted and then Applied once the effective date is reached.
+type Amendment struct {
+	ID                 string         `json:"ID"`
+	ContractID         string         `json:"Contract ID"`
+	Status             string         `json:"Status"`      // Can only be Pending, Accepted, Applied, or Rejected.
+	ProposedBy         string         `json:"Proposed by"` // Either employer or employee.
+	ProposedDate       string         `json:"Proposed date"`
+	EffectiveDate      string         `json:"Effective date"`
+	DecisionDate       string         `json:"Decision date,omitempty" metadata:"Decision date,optional"`
+	AppliedDate        string         `json:"Applied date,omitempty" metadata:"Applied date,optional"`
+	Terms              AmendmentTerms `json:"Terms"` // The salary, housing, allowances, and contact details are empty in the world state.
+	PrivateDetailsHash string         `json:"Private details hash"`
+	// Base is the terms of the contract when the amendment was proposed. Only the terms that differ from it are applied, see applyAmendment.
+	// Empty for amendments proposed before it was kept.
+	Base *AmendmentTerms `json:"Base,omitempty" metadata:"Base,optional"`
+	// Compliance lists the rule packs the amended terms were checked against when proposed. The merged terms are checked again
+	// when the amendment is accepted and when it is applied, see amendedContract.
+	Compliance []RulePackVersion `json:"Compliance,omitempty" metadata:"Compliance,optional"`
+	// RejectionReason is set when an Accepted amendment breaks the rule packs by its effective date, so ApplyDueAmendments rejected it.
+	RejectionReason string `json:"Rejection reason,omitempty" metadata:"Rejection reason,optional"`
+}
+
+// Will return the current terms of the contract.
+func termsOf(contract *Contract) AmendmentTerms {
+	return AmendmentTerms{
+		Notes:            contract.Notes,
+		StartDate:        contract.StartDate,
+		EndDate:          contract.EndDate,
+		ExtensionDetails: contract.ExtensionDetails,
+		Employer:         contract.Employer,
+		Employee:         contract.Employee,
+		Job:              contract.Job,
+		Benefits:         contract.Benefits,
+	}
+}
+
+// Will replace the terms of the contract with the amended ones.
+func (t AmendmentTerms) applyTo(contract *Contract) {
+	contract.Notes = t.Notes
+	contract.StartDate = t.StartDate
+	contract.EndDate = t.EndDate
+	contract.ExtensionDetails = t.ExtensionDetails
+	contract.Employer = t.Employer
+	contract.Employee = t.Employee
+	contract.Job = t.Job
+	contract.Benefits = t.Benefits
+}
+
+// Will return the terms of the contract with the changes the amendment makes from its Base. The other terms keep their current value,
+// so changes made to the contract after the amendment was proposed, such as an extension, are not undone.
+// Amendments without a Base replace every term.
+func (a *Amendment) mergedTerms(contract *Contract) AmendmentTerms {
+	if a.Base == nil {
+		return a.Terms
+	}
+	merged, base, proposed := termsOf(contract), *a.Base, a.Terms
+	if proposed.Notes != base.Notes {
+		merged.Notes = proposed.Notes
+	}
+	if proposed.StartDate != base.StartDate {
+		merged.StartDate = proposed.StartDate
+	}
+	if proposed.EndDate != base.EndDate {
+		merged.EndDate = proposed.EndDate
+	}
+	if proposed.ExtensionDetails != base.ExtensionDetails {
+		merged.ExtensionDetails = proposed.ExtensionDetails
+	}
+	if !sameJSON(proposed.Employer, base.Employer) {
+		merged.Employer = proposed.Employer
+	}
+	if !sameJSON(proposed.Employee, base.Employee) {
+		merged.Employee = proposed.Employee
+	}
+	if !sameJSON(proposed.Job, base.Job) {
+		merged.Job = proposed.Job
+	}
+	if !sameJSON(proposed.Benefits, base.Benefits) {
+		merged.Benefits = proposed.Benefits
+	}
+	return merged
+}
+
+// Will return true if both values have the same JSON encoding.
+func sameJSON(a interface{}, b interface{}) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
+
+// Will return the party that must accept or reject an amendment proposed by the given party.
+func counterparty(party string) string {
+	if party == asEmployer {
+		return asEmployee
+	}
+	return asEmployer
+}
+
+func amendmentKey(ctx contractapi.TransactionContextInterface, contractID string, amendmentID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(amendmentKeyType, []string{contractID, amendmentID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", amendmentKeyType, err)
+	}
+	return key, nil
+}
+
+func putAmendment(ctx contractapi.TransactionContextInterface, amendment *Amendment) error {
+	key, err := amendmentKey(ctx, amendment.ContractID, amendment.ID)
+	if err != nil {
+		return err
+	}
+	amendmentJSON, err := json.Marshal(amendment)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, amendmentJSON)
+}
+
+// getAmendment returns the amendment record with the given ID. Will return an error if the contract has no such amendment.
+func getAmendment(ctx contractapi.TransactionContextInterface, contractID string, amendmentID string) (*Amendment, error) {
+	key, err := amendmentKey(ctx, contractID, amendmentID)
+	if err != nil {
+		return nil, err
+	}
+	amendmentJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if amendmentJSON == nil {
+		return nil, fmt.Errorf("There is no matching amendments with the given ID: %s ", amendmentID)
+	}
+	var amendment Amendment
+	if err := json.Unmarshal(amendmentJSON, &amendment); err != nil {
+		return nil, err
+	}
+	return &amendment, nil
+}
+
+// listAmendments returns every amendment of the contract ordered by ID.
+func listAmendments(ctx contractapi.TransactionContextInterface, contractID string) ([]Amendment, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(amendmentKeyType, []string{contractID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	amendments := []Amendment{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var amendment Amendment
+		if err := json.Unmarshal(queryResponse.Value, &amendment); err != nil {
+			return nil, err
+		}
+		amendments = append(amendments, amendment)
+	}
+
+	sort.SliceStable(amendments, func(i, j int) bool {
+		return lessID(amendments[i].ID, amendments[j].ID)
+	})
+	return amendments, nil
+}
+
+// checkAmendable will return an error unless the contract is Active or Suspended, the only statuses whose terms can be amended.
+func checkAmendable(contract *Contract) error {
+	if contract.Status != StatusActive && contract.Status != StatusSuspended {
+		return fmt.Errorf("Only Active or Suspended contracts can be amended. The contract is %s", contract.Status)
+	}
+	return nil
+}
+
+/*
+* This method will store a change to the terms of an Active or Suspended contract until the other party accepts or rejects it.
+* @param jsonString represents the amended contract, in the same format as UpdateContract. The private details come from the transient map.
+* @param EffectiveDate is the date the amendment applies from. It can't be in the past or after the amended End date.
+* A contract can only have one Pending or Accepted amendment at a time.
+ */
+func (s *SmartContract) ProposeAmendment(ctx contractapi.TransactionContextInterface, jsonString string, EffectiveDate string) (bool, error) {
+
+	//Parsing jsonString
+	jsonString = strings.ReplaceAll(jsonString, "'", "\"")
+	var proposed Contract
+	err := json.Unmarshal([]byte(jsonString), &proposed)
+	if err != nil {
+		return false, fmt.Errorf("Error Unmarshaling JSON: %s, \n %s", err, jsonString)
+	}
+	if err := checkArgumentBenefits([]byte(jsonString)); err != nil {
+		return false, err
+	}
+	if err := checkArgumentEmployee(&proposed); err != nil {
+		return false, err
+	}
+
+	contract, err := readContractFor(ctx, "ProposeAmendment", proposed.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := checkAmendable(contract); err != nil {
+		return false, err
+	}
+	// The salary and contact details come from the transient map, see privatedata.go.
+	private, err := readPrivateDetails(ctx)
+	if err != nil {
+		return false, err
+	}
+	mergePrivateDetails(&proposed, private)
+
+	// The names and countries of the parties come from the registry, see registry.go.
+	if err := resolveParties(ctx, &proposed, private); err != nil {
+		return false, err
+	}
+	// Compared once the employee ID of the proposal is replaced with its pseudonym.
+	if proposed.Employer.ID != contract.Employer.ID || proposed.Employee.ID != contract.Employee.ID {
+		return false, fmt.Errorf("An amendment can't change the employer or the employee of the contract.")
+	}
+
+	// Each create method will check for the necessary information and conditions.
+	employer, flag1 := createEmployer(ctx, proposed.Employer.ID, proposed.Employer.Name, proposed.Employer.EmployerAC, proposed.Employer.Country)
+	employee, flag2 := createEmployee(ctx, proposed.Employee.ID, proposed.Employee.Name, proposed.Employee.EmployeeAC, proposed.Employee.Country)
+	job, flag3 := createJob(ctx, proposed.Job.Position, proposed.Job.Level, proposed.Job.Description, proposed.Job.ProbationMonths)
+	benefits, flag4 := createBenefits(ctx, proposed.Benefits.Currency, proposed.Benefits.Salary, proposed.Benefits.AnnualIncrease, proposed.Benefits.AnnualLeave,
+		proposed.Benefits.Housing, proposed.Benefits.Allowances, proposed.Benefits.OtherBenefits)
+	if !(flag1 && flag2 && flag3 && flag4) {
+		return false, fmt.Errorf("Failed to initialize one of the structs.")
+	}
+	if err := benefits.validate(); err != nil {
+		return false, err
+	}
+
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, err := checkDate(currentDate, proposed.StartDate, proposed.EndDate); err != nil {
+		return false, err
+	}
+	compliance, err := checkCompliance(ctx, &Contract{ID: contract.ID, StartDate: proposed.StartDate, EndDate: proposed.EndDate,
+		Employer: employer, Employee: employee, Job: job, Benefits: benefits})
+	if err != nil {
+		return false, err
+	}
+	effectiveDate, err := time.Parse(dateFormat, EffectiveDate)
+	if err != nil {
+		return false, fmt.Errorf("the effective date must be in MM/DD/YYYY format: %v", err)
+	}
+	if effectiveDate.Before(currentDate.Truncate(24 * time.Hour)) {
+		return false, fmt.Errorf("the effective date can't be in the past")
+	}
+	endDate, err := time.Parse(dateFormat, proposed.EndDate)
+	if err != nil {
+		return false, fmt.Errorf("the End date must be in MM/DD/YYYY format: %v", err)
+	}
+	if effectiveDate.After(endDate) {
+		return false, fmt.Errorf("the effective date can't be after the End date of the amended contract")
+	}
+
+	amendments, err := listAmendments(ctx, contract.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, amendment := range amendments {
+		if amendment.Status == "Pending" || amendment.Status == "Accepted" {
+			return false, fmt.Errorf("The contract already has the %s amendment %s.", amendment.Status, amendment.ID)
+		}
+	}
+
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	proposedBy := asEmployee
+	if caller.is(asEmployer, contract) {
+		proposedBy = asEmployer
+	}
+
+	amendment := Amendment{
+		ID:            strconv.Itoa(len(amendments)),
+		ContractID:    contract.ID,
+		Status:        "Pending",
+		ProposedBy:    proposedBy,
+		ProposedDate:  currentDate.Format(dateFormat),
+		EffectiveDate: EffectiveDate,
+		Compliance:    compliance,
+		Terms: AmendmentTerms{
+			Notes:            proposed.Notes,
+			StartDate:        proposed.StartDate,
+			EndDate:          proposed.EndDate,
+			ExtensionDetails: proposed.ExtensionDetails,
+			Employer:         employer,
+			Employee:         employee,
+			Job:              job,
+			Benefits:         benefits,
+		},
+	}
+
+	// The proposed private details stay in the private collection until the amendment is applied.
+	key, err := amendmentKey(ctx, amendment.ContractID, amendment.ID)
+	if err != nil {
+		return false, err
+	}
+	amendment.PrivateDetailsHash, err = private.hash()
+	if err != nil {
+		return false, err
+	}
+	privateJSON, err := json.Marshal(private)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollection, key, privateJSON); err != nil {
+		return false, fmt.Errorf("failed to write the private details: %v", err)
+	}
+	amendedContract := Contract{}
+	amendment.Terms.applyTo(&amendedContract)
+	stripPrivateDetails(&amendedContract)
+	amendment.Terms = termsOf(&amendedContract)
+	base := termsOf(contract)
+	amendment.Base = &base
+
+	if err := putAmendment(ctx, &amendment); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventAmendmentProposed, contract, amendment.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// getAmendmentForDecision reads the contract and a Pending amendment, and checks that the caller is the party that must decide on it.
+func getAmendmentForDecision(ctx contractapi.TransactionContextInterface, transaction string, ID string, AmendmentID string) (*Contract, *Amendment, error) {
+	contract, err := readContractFor(ctx, transaction, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkAmendable(contract); err != nil {
+		return nil, nil, err
+	}
+	amendment, err := getAmendment(ctx, ID, AmendmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if amendment.Status != "Pending" {
+		return nil, nil, fmt.Errorf("The amendment %s is already %s.", AmendmentID, amendment.Status)
+	}
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !caller.is(counterparty(amendment.ProposedBy), contract) {
+		return nil, nil, fmt.Errorf("access denied: only the %s of contract %s can decide on an amendment proposed by the %s", counterparty(amendment.ProposedBy), ID, amendment.ProposedBy)
+	}
+	return contract, amendment, nil
+}
+
+/*
+* This method will accept a Pending amendment. Only the party that did not propose it can accept it.
+* The amendment is applied right away if its effective date has been reached, otherwise by ApplyDueAmendments.
+* It is refused if the terms it changes, merged into the current contract, break the latest rule packs.
+* @Param ExpectedRevision is the revision of the contract the caller compared the amendment with.
+ */
+func (s *SmartContract) AcceptAmendment(ctx contractapi.TransactionContextInterface, ID string, AmendmentID string, ExpectedRevision int) (bool, error) {
+	contract, amendment, err := getAmendmentForDecision(ctx, "AcceptAmendment", ID, AmendmentID)
+	if err != nil {
+		return false, err
+	}
+	if err := checkRevision("contract", ID, contract.Revision, ExpectedRevision); err != nil {
+		return false, err
+	}
+	if _, _, err := amendedContract(ctx, contract, amendment); err != nil {
+		return false, err
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	amendment.Status = "Accepted"
+	amendment.DecisionDate = currentDate.Format(dateFormat)
+
+	event := EventAmendmentAccepted
+	applied, err := applyAmendment(ctx, contract, amendment, currentDate)
+	if err != nil {
+		return false, err
+	}
+	if applied {
+		event = EventContractAmended
+	} else if err := putAmendment(ctx, amendment); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, event, contract, amendment.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RejectAmendment refuses a Pending amendment. Only the party that did not propose it can reject it.
+func (s *SmartContract) RejectAmendment(ctx contractapi.TransactionContextInterface, ID string, AmendmentID string) (bool, error) {
+	contract, amendment, err := getAmendmentForDecision(ctx, "RejectAmendment", ID, AmendmentID)
+	if err != nil {
+		return false, err
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	amendment.Status = "Rejected"
+	amendment.DecisionDate = currentDate.Format(dateFormat)
+
+	if err := putAmendment(ctx, amendment); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventAmendmentRejected, contract, amendment.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// amendedContract returns the contract with the terms the amendment changes merged in, see Amendment.mergedTerms,
+// and the private details of the amendment. The contract and the rule packs can have changed since the amendment was proposed,
+// so the merged terms are checked against the latest rule packs again. Will return a ComplianceError if they break them.
+func amendedContract(ctx contractapi.TransactionContextInterface, contract *Contract, amendment *Amendment) (*Contract, *ContractPrivateDetails, error) {
+	key, err := amendmentKey(ctx, amendment.ContractID, amendment.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateJSON, err := ctx.GetStub().GetPrivateData(privateCollection, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the private details: %v", err)
+	}
+	if privateJSON == nil {
+		return nil, nil, fmt.Errorf("the private details of amendment %s are missing", amendment.ID)
+	}
+	var private ContractPrivateDetails
+	if err := json.Unmarshal(privateJSON, &private); err != nil {
+		return nil, nil, err
+	}
+
+	amended := *contract
+	amendment.mergedTerms(contract).applyTo(&amended)
+	terms := amended
+	mergePrivateDetails(&terms, &private)
+	if amended.Compliance, err = checkCompliance(ctx, &terms); err != nil {
+		return nil, nil, err
+	}
+	return &amended, &private, nil
+}
+
+// applyAmendment writes the amended terms to the contract if the amendment is Accepted and its effective date has been reached.
+// Only the terms the amendment changes are written, see amendedContract. The private details are replaced in full.
+// Will return true if the amendment was applied.
+func applyAmendment(ctx contractapi.TransactionContextInterface, contract *Contract, amendment *Amendment, currentDate time.Time) (bool, error) {
+	effectiveDate, err := time.Parse(dateFormat, amendment.EffectiveDate)
+	if err != nil {
+		return false, err
+	}
+	if amendment.Status != "Accepted" || currentDate.Before(effectiveDate) {
+		return false, nil
+	}
+
+	amended, private, err := amendedContract(ctx, contract, amendment)
+	if err != nil {
+		return false, err
+	}
+	*contract = *amended
+	if err := putPrivateDetails(ctx, contract, private, amendment.EffectiveDate); err != nil {
+		return false, err
+	}
+	stripPrivateDetails(contract)
+
+	if err := putContract(ctx, contract); err != nil {
+		return false, err
+	}
+
+	amendment.Status = "Applied"
+	amendment.AppliedDate = currentDate.Format(dateFormat)
+	if err := putAmendment(ctx, amendment); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will apply the Accepted amendments whose effective date has been reached, and return the IDs of the amended contracts.
+* @Param batchSize is the maximum number of amendments applied by one call. Call it again until it returns no IDs.
+* Only authorities can run it.
+ */
+func (s *SmartContract) ApplyDueAmendments(ctx contractapi.TransactionContextInterface, batchSize int) ([]string, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if caller.Role != RoleAuthority {
+		return nil, fmt.Errorf("access denied: caller with role %s from %s can not call ApplyDueAmendments", caller.Role, caller.MSPID)
+	}
+	if batchSize < 1 {
+		return nil, fmt.Errorf("the batch size must be at least 1")
+	}
+
+	now, err := s.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(amendmentKeyType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	applied := []string{}
+	for resultsIterator.HasNext() && len(applied) < batchSize {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var amendment Amendment
+		if err := json.Unmarshal(queryResponse.Value, &amendment); err != nil {
+			return nil, err
+		}
+		if amendment.Status != "Accepted" {
+			continue
+		}
+
+		contractJSON, err := ctx.GetStub().GetState(amendment.ContractID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if contractJSON == nil {
+			continue
+		}
+		var contract Contract
+		if err := json.Unmarshal(contractJSON, &contract); err != nil {
+			return nil, err
+		}
+		// A contract that ended before the effective date keeps its old terms.
+		if contract.Status != StatusActive && contract.Status != StatusSuspended {
+			continue
+		}
+
+		ok, err := applyAmendment(ctx, &contract, &amendment, now)
+		var complianceErr *ComplianceError
+		if errors.As(err, &complianceErr) {
+			// The rule packs changed since the amendment was accepted. It is rejected, so it doesn't hold up the other amendments.
+			amendment.Status = "Rejected"
+			amendment.RejectionReason = complianceErr.Error()
+			if err := putAmendment(ctx, &amendment); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			applied = append(applied, contract.ID)
+		}
+	}
+	return applied, nil
+}
+
+/*
+* This method will return the amendments of the contract with the given ID.
+* @Param status can be Pending, Accepted, Applied, or Rejected to filter the amendments, or empty to return all of them.
+* The private details are merged into the terms for the callers allowed to read them.
+ */
+func (s *SmartContract) GetAmendments(ctx contractapi.TransactionContextInterface, ID string, status string) ([]Amendment, error) {
+	if status != "" && status != "Pending" && status != "Accepted" && status != "Applied" && status != "Rejected" {
+		return nil, fmt.Errorf("unknown amendment status: %s. It can only be Pending, Accepted, Applied, Rejected, or empty", status)
+	}
+
+	contract, err := readContractFor(ctx, "GetAmendments", ID)
+	if err != nil {
+		return nil, err
+	}
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	amendments, err := listAmendments(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	matching := []Amendment{}
+	for _, amendment := range amendments {
+		if status != "" && amendment.Status != status {
+			continue
+		}
+		if caller.can("ReadPrivateDetails", contract) {
+			key, err := amendmentKey(ctx, ID, amendment.ID)
+			if err != nil {
+				return nil, err
+			}
+			privateJSON, err := ctx.GetStub().GetPrivateData(privateCollection, key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the private details: %v", err)
+			}
+			if privateJSON != nil {
+				var private ContractPrivateDetails
+				if err := json.Unmarshal(privateJSON, &private); err != nil {
+					return nil, err
+				}
+				amended := Contract{}
+				amendment.Terms.applyTo(&amended)
+				mergePrivateDetails(&amended, &private)
+				amendment.Terms = termsOf(&amended)
+			}
+		}
+		matching = append(matching, amendment)
+	}
+	return matching, nil
+}
@@ -0,0 +1,82 @@
+package chaincode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// proposeNotes has the employer propose an amendment of the contract of testContractJSON that only changes its notes.
+func (l *testLedger) proposeNotes(ID string, notes string, effectiveDate string) {
+	l.t.Helper()
+	amendmentJSON := strings.NewReplacer("'ID': '11930'", "'ID': '"+ID+"'", "'Notes': 'N/A'", "'Notes': '"+notes+"'").Replace(testContractJSON)
+	l.ok(l.contract.ProposeAmendment(l.as(testEmployer), amendmentJSON, effectiveDate))
+}
+
+// amendment returns the amendment of the contract with the given ID.
+func (l *testLedger) amendment(ID string, amendmentID string) Amendment {
+	l.t.Helper()
+	amendments, err := l.contract.GetAmendments(l.as(testEmployer), ID, "")
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	for _, amendment := range amendments {
+		if amendment.ID == amendmentID {
+			return amendment
+		}
+	}
+	l.t.Fatalf("contract %s has no amendment %s", ID, amendmentID)
+	return Amendment{}
+}
+
+func TestAmendmentComplianceRecheck(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+	l.activeContract("11930", "01/02/2026", "05/05/2028")
+	l.proposeNotes("11930", "Remote", "03/01/2026")
+
+	// The rule packs change after the amendment is proposed.
+	strict := `{'Country': 'Saudi Arabia','Minimum salaries': [{'Level': 'Senior','Minimum': {'Minor units': 1200000,'Currency': 'SAR'}}]}`
+	l.ok(l.contract.SetRulePack(l.as(testRegulatorA), strict, 0))
+	revision := l.read("11930").Revision
+	_, err := l.contract.AcceptAmendment(l.as(testEmployee), "11930", "0", revision)
+	var compliance *ComplianceError
+	if !errors.As(err, &compliance) {
+		t.Fatalf("want a compliance error, got %v", err)
+	}
+	if status := l.amendment("11930", "0").Status; status != "Pending" {
+		t.Fatalf("want the amendment still Pending, got %s", status)
+	}
+
+	lenient := `{'Country': 'Saudi Arabia','Minimum salaries': [{'Level': '*','Minimum': {'Minor units': 800000,'Currency': 'SAR'}}]}`
+	l.ok(l.contract.SetRulePack(l.as(testRegulatorA), lenient, 1))
+	l.ok(l.contract.AcceptAmendment(l.as(testEmployee), "11930", "0", revision))
+
+	// The rule packs change again before the effective date, so the amendment is rejected instead of applied.
+	strict = strings.Replace(strict, "'Senior'", "'*'", 1)
+	l.ok(l.contract.SetRulePack(l.as(testRegulatorA), strict, 2))
+	l.setDate("03/01/2026")
+	applied, err := l.contract.ApplyDueAmendments(l.as(testAuthorityA), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("want no amendment applied, got %v", applied)
+	}
+	amendment := l.amendment("11930", "0")
+	if amendment.Status != "Rejected" || !strings.Contains(amendment.RejectionReason, "[Saudi Arabia min-salary v3]") {
+		t.Fatalf("want the amendment rejected for the v3 minimum salary, got %s: %s", amendment.Status, amendment.RejectionReason)
+	}
+	if contract := l.read("11930"); contract.Notes != "N/A" {
+		t.Fatalf("want the contract to keep its notes, got %s", contract.Notes)
+	}
+
+	// Once the rules allow it, a new amendment is checked against the latest rule packs and applied.
+	l.ok(l.contract.SetRulePack(l.as(testRegulatorA), lenient, 3))
+	l.proposeNotes("11930", "Hybrid", "03/01/2026")
+	revision = l.read("11930").Revision
+	l.ok(l.contract.AcceptAmendment(l.as(testEmployee), "11930", "1", revision))
+	contract := l.read("11930")
+	if contract.Notes != "Hybrid" || len(contract.Compliance) != 1 || contract.Compliance[0] != (RulePackVersion{"Saudi Arabia", 4}) {
+		t.Fatalf("want the amendment applied and checked against v4 of the rules, got %s %v", contract.Notes, contract.Compliance)
+	}
+}
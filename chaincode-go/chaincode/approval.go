@@ -0,0 +1,119 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Approval: records that one of the required parties approved the contract.
+type Approval struct {
+	Party string `json:"Party"` // Either a country MSP ID, employer, or employee. See requiredApprovals.
+	Date  string `json:"Date"`
+}
+
+// requiredApprovals returns the parties that must approve a contract before it becomes Active.
+// Overseas contracts need both countries, while contracts inside one country need both the employer and the employee.
+func requiredApprovals(contract *Contract) []string {
+	employerMSP := countryMSP(contract.Employer.Country)
+	employeeMSP := countryMSP(contract.Employee.Country)
+	if employerMSP != employeeMSP {
+		return []string{employerMSP, employeeMSP}
+	}
+	return []string{asEmployer, asEmployee}
+}
+
+// approvalParty returns the required party the caller approves for. Will return an error if the caller is not a required approver.
+func approvalParty(ctx contractapi.TransactionContextInterface, contract *Contract) (string, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, party := range contract.RequiredApprovals {
+		switch party {
+		case asEmployer, asEmployee:
+			if caller.is(party, contract) {
+				return party, nil
+			}
+		default:
+			if caller.Role == RoleAuthority && caller.MSPID == party {
+				return party, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("access denied: caller with role %s from %s is not a required approver of contract %s", caller.Role, caller.MSPID, contract.ID)
+}
+
+// Will return true if the given party has already approved the contract.
+func hasApproved(contract *Contract, party string) bool {
+	for _, approval := range contract.Approvals {
+		if approval.Party == party {
+			return true
+		}
+	}
+	return false
+}
+
+// Will return true if every required party approved the contract.
+func isFullyApproved(contract *Contract) bool {
+	for _, party := range contract.RequiredApprovals {
+		if !hasApproved(contract, party) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+* ID represents the contract ID.
+* This method will remove the caller approval from a Pending contract.
+* Will return true only if the caller has approved the contract before.
+ */
+func (s *SmartContract) WithdrawApproval(ctx contractapi.TransactionContextInterface, ID string) (bool, error) {
+
+	contract, err := readContractFor(ctx, "WithdrawApproval", ID)
+	if err != nil {
+		return false, err
+	}
+
+	if contract.Status != StatusPending {
+		return false, fmt.Errorf("You can only withdraw an approval from a Pending contract. The contract is %s", contract.Status)
+	}
+	if len(contract.RequiredApprovals) == 0 { // Contracts created before approvals were added.
+		contract.RequiredApprovals = requiredApprovals(contract)
+	}
+
+	party, err := approvalParty(ctx, contract)
+	if err != nil {
+		return false, err
+	}
+	if !hasApproved(contract, party) {
+		return false, fmt.Errorf("%s has not approved the contract %s", party, ID)
+	}
+
+	approvals := []Approval{}
+	for _, approval := range contract.Approvals {
+		if approval.Party != party {
+			approvals = append(approvals, approval)
+		}
+	}
+	contract.Approvals = approvals
+
+	if err := putContract(ctx, contract); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventApprovalWithdrawn, contract, contract.Status, ""); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Will add the approval of the given party with the given date.
+func addApproval(contract *Contract, party string, date time.Time) {
+	contract.Approvals = append(contract.Approvals, Approval{
+		Party: party,
+		Date:  date.Format(dateFormat),
+	})
+}
@@ -0,0 +1,131 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Ruling: the decision of an arbitrator on an escalated dispute.
+// A dispute moves from Active to Escalated (EscalateDispute), to Ruled (RecordRuling), and to Closed (EnforceRuling).
+type Ruling struct {
+	Arbitrator   string `json:"Arbitrator"` // The MSP ID of the arbitrator organization.
+	Decision     string `json:"Decision"`
+	Date         string `json:"Date"`
+	Enforced     bool   `json:"Enforced"`
+	EnforcedBy   string `json:"Enforced by,omitempty" metadata:"Enforced by,optional"` // The MSP ID of whoever enforced the ruling.
+	EnforcedDate string `json:"Enforced date,omitempty" metadata:"Enforced date,optional"`
+}
+
+// Will return true if the dispute is waiting for a ruling. The employer can't respond to it until then.
+func isLocked(dispute *Dispute) bool {
+	return dispute.Status == "Escalated" && dispute.Ruling == nil
+}
+
+/*
+* This method will send an Active dispute to the arbitrators. Either the employee or the employer can escalate it.
+* The employer can't respond to the dispute until a ruling is recorded.
+ */
+func (s *SmartContract) EscalateDispute(ctx contractapi.TransactionContextInterface, ID string, DisputeID string, ExpectedRevision int) (bool, error) {
+	contract, dispute, err := getContractDispute(ctx, "EscalateDispute", ID, DisputeID, ExpectedRevision)
+	if err != nil {
+		return false, err
+	}
+	if dispute.Status != "Active" {
+		return false, fmt.Errorf("Only Active disputes can be escalated. The dispute is %s", dispute.Status)
+	}
+
+	curDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	dispute.Status = "Escalated"
+	dispute.EscalatedDate = curDate.Format(dateFormat)
+	dispute.LastUpdatedDate = curDate.Format(dateFormat)
+
+	if err := putDispute(ctx, ID, dispute); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventDisputeEscalated, contract, contract.Status, DisputeID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will record the arbitrator decision on an Escalated dispute, and unlock it.
+* @Param Decision must not be empty. A ruling can't be changed once it is recorded.
+ */
+func (s *SmartContract) RecordRuling(ctx contractapi.TransactionContextInterface, ID string, DisputeID string, Decision string, ExpectedRevision int) (bool, error) {
+	if strings.TrimSpace(Decision) == "" {
+		return false, fmt.Errorf("the decision of a ruling can not be empty")
+	}
+	contract, dispute, err := getContractDispute(ctx, "RecordRuling", ID, DisputeID, ExpectedRevision)
+	if err != nil {
+		return false, err
+	}
+	if dispute.Status != "Escalated" {
+		return false, fmt.Errorf("Only Escalated disputes can be ruled on. The dispute is %s", dispute.Status)
+	}
+
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	curDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	dispute.Status = "Ruled"
+	dispute.LastUpdatedDate = curDate.Format(dateFormat)
+	dispute.Ruling = &Ruling{
+		Arbitrator: caller.MSPID,
+		Decision:   Decision,
+		Date:       curDate.Format(dateFormat),
+	}
+
+	if err := putDispute(ctx, ID, dispute); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventRulingRecorded, contract, contract.Status, DisputeID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will mark the ruling of a Ruled dispute as enforced, and close the dispute.
+* Arbitrators and the authorities of the employer's or the employee's country can enforce a ruling.
+ */
+func (s *SmartContract) EnforceRuling(ctx contractapi.TransactionContextInterface, ID string, DisputeID string, ExpectedRevision int) (bool, error) {
+	contract, dispute, err := getContractDispute(ctx, "EnforceRuling", ID, DisputeID, ExpectedRevision)
+	if err != nil {
+		return false, err
+	}
+	if dispute.Status != "Ruled" || dispute.Ruling == nil {
+		return false, fmt.Errorf("Only Ruled disputes can be enforced. The dispute is %s", dispute.Status)
+	}
+
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	curDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	dispute.Status = "Closed"
+	dispute.LastUpdatedDate = curDate.Format(dateFormat)
+	dispute.Ruling.Enforced = true
+	dispute.Ruling.EnforcedBy = caller.MSPID
+	dispute.Ruling.EnforcedDate = curDate.Format(dateFormat)
+
+	if err := putDispute(ctx, ID, dispute); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventRulingEnforced, contract, contract.Status, DisputeID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
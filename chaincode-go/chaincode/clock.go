@@ -0,0 +1,39 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// The format of every date stored in a contract.
+const dateFormat = "01/02/2006"
+
+// Clock returns the current time of a transaction. SmartContract uses TxClock unless another Clock is set,
+// so the date rules can be checked against fixed dates.
+type Clock func(ctx contractapi.TransactionContextInterface) (time.Time, error)
+
+// TxClock returns the transaction timestamp. Every endorsing peer gets the same value, unlike time.Now().
+func TxClock(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the transaction timestamp: %v", err)
+	}
+	return time.Unix(timestamp.Seconds, int64(timestamp.Nanos)).UTC(), nil
+}
+
+// FixedClock returns a Clock that always returns the given time.
+func FixedClock(now time.Time) Clock {
+	return func(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+		return now, nil
+	}
+}
+
+// now returns the current time from the contract clock.
+func (s *SmartContract) now(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	if s.Clock == nil {
+		return TxClock(ctx)
+	}
+	return s.Clock(ctx)
+}
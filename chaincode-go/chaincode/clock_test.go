@@ -0,0 +1,113 @@
+package chaincode
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestClock(t *testing.T) {
+	txTime := time.Date(2026, 6, 15, 10, 30, 0, 0, time.UTC)
+	fixed := time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		clock Clock
+		want  time.Time
+	}{
+		{"TxClock is the default", nil, txTime},
+		{"TxClock", TxClock, txTime},
+		{"FixedClock", FixedClock(fixed), fixed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stub := shimtest.NewMockStub("contracts", nil)
+			stub.MockTransactionStart("tx1")
+			stub.TxTimestamp = &timestamp.Timestamp{Seconds: txTime.Unix()}
+			ctx := &contractapi.TransactionContext{}
+			ctx.SetStub(stub)
+
+			s := &SmartContract{Clock: test.clock}
+			got, err := s.now(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.want) {
+				t.Fatalf("want %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckDate(t *testing.T) {
+	today := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		wantErr   string
+	}{
+		{"future contract", "04/01/2026", "04/01/2028", ""},
+		{"started in the past", "01/01/2025", "04/01/2028", ""},
+		{"ends today", "01/01/2025", "03/01/2026", ""},
+		{"ended yesterday", "01/01/2025", "02/28/2026", "You can't create a new contract in the past"},
+		{"start date not MM/DD/YYYY", "2026-04-01", "04/01/2028", "parsing time"},
+		{"end date not MM/DD/YYYY", "04/01/2026", "13/01/2028", "parsing time"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, err := checkDate(today, test.startDate, test.endDate)
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("want an error with %q, got %v", test.wantErr, err)
+			}
+			if ok != (test.wantErr == "") {
+				t.Fatalf("want ok %v, got %v", test.wantErr == "", ok)
+			}
+		})
+	}
+}
+
+func TestExtensionCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		today   string
+		toDate  string
+		endDate string
+		wantErr string
+	}{
+		{"in the last three months", "03/01/2028", "05/05/2029", "05/05/2028", ""},
+		{"on the end date", "05/05/2028", "05/05/2029", "05/05/2028", ""},
+		{"to the same end date", "03/01/2028", "05/05/2028", "05/05/2028", ""},
+		{"too early", "01/01/2028", "05/05/2029", "05/05/2028", "You can only extend the contract in the last three months."},
+		{"shorter", "03/01/2028", "04/01/2028", "05/05/2028", "You can't shorten the length of the contract"},
+		{"to the past", "03/01/2028", "02/01/2028", "05/05/2028", "You can't extend the contract to a date in the past"},
+		{"to today", "03/01/2028", "03/01/2028", "03/01/2028", "You can't extend the contract to a date in the past"},
+		{"date not MM/DD/YYYY", "03/01/2028", "2029-05-05", "05/05/2028", "parsing time"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			today, err := time.Parse(dateFormat, test.today)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := extensionCheck(today, test.toDate, test.endDate)
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("want an error with %q, got %v", test.wantErr, err)
+			}
+			if ok != (test.wantErr == "") {
+				t.Fatalf("want ok %v, got %v", test.wantErr == "", ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Every version of a rule pack is stored as its own record under this composite key object type, so older versions stay readable.
+const rulePackKeyType = "compliance~pack" // country, version
+
+// RulePack: the labor rules of one country, set by its regulators. Every rule is optional, and a rule left empty is not enforced.
+// A contract must meet the rule packs of both the employer's and the employee's country.
+type RulePack struct {
+	Country            string         `json:"Country"` // As written in Employer.Country and Employee.Country.
+	Version            int            `json:"Version"` // Goes up by one with every update. 0 means the country has no rules yet.
+	MinimumSalaries    []LevelMinimum `json:"Minimum salaries"`
+	MaxContractMonths  int            `json:"Max contract months,omitempty" metadata:"Max contract months,optional"`
+	MinimumHousing     *Money         `json:"Minimum housing,omitempty" metadata:"Minimum housing,optional"` // Housing is mandatory when set, even at 0.
+	MinimumLeaveDays   int            `json:"Minimum annual leave days,omitempty" metadata:"Minimum annual leave days,optional"`
+	MaxProbationMonths int            `json:"Max probation months,omitempty" metadata:"Max probation months,optional"`
+	UpdatedBy          string         `json:"Updated by"` // The MSP ID of the regulator that set this version.
+	UpdatedDate        string         `json:"Updated date"`
+}
+
+// LevelMinimum: the lowest monthly salary for a job level, in one currency. Minimums are not converted, so a level can have one minimum
+// per currency it may be paid in. A contract paid in a currency its level has no minimum in is not held to one.
+type LevelMinimum struct {
+	Level   string `json:"Level"` // Matched with Job.Level, ignoring case. "*" applies to every level that has no minimum of its own.
+	Minimum Money  `json:"Minimum"`
+}
+
+// RulePackVersion: the version of a country's rule pack a contract was checked against.
+type RulePackVersion struct {
+	Country string `json:"Country"`
+	Version int    `json:"Version"`
+}
+
+// ComplianceViolation: a contract term that breaks a rule of a country's rule pack.
+type ComplianceViolation struct {
+	RuleID  string `json:"Rule ID"` // One of the IDs in complianceRules.
+	Country string `json:"Country"`
+	Version int    `json:"Version"`
+	Message string `json:"Message"`
+}
+
+// ComplianceError is returned when a contract breaks one or more rules. It lists every violation, not only the first one.
+type ComplianceError struct {
+	Violations []ComplianceViolation
+}
+
+func (e *ComplianceError) Error() string {
+	messages := []string{}
+	for _, violation := range e.Violations {
+		messages = append(messages, fmt.Sprintf("[%s %s v%d] %s", violation.Country, violation.RuleID, violation.Version, violation.Message))
+	}
+	return fmt.Sprintf("compliance violations: %s", strings.Join(messages, "; "))
+}
+
+// complianceRule: one check a rule pack can enforce. check returns why the contract breaks the rule, or an empty string.
+// New rules only need a field in RulePack and an entry in complianceRules.
+type complianceRule struct {
+	ID    string
+	check func(pack *RulePack, contract *Contract) string
+}
+
+// complianceRules lists every rule in the order the violations are reported.
+var complianceRules = []complianceRule{
+	{"min-salary", checkMinimumSalary},
+	{"max-contract-length", checkContractLength},
+	{"min-housing", checkMinimumHousing},
+	{"min-annual-leave", checkMinimumLeave},
+	{"max-probation", checkProbation},
+}
+
+func checkMinimumSalary(pack *RulePack, contract *Contract) string {
+	// The minimum of the level itself in the salary currency, or else the one for every level.
+	salary := contract.Benefits.Salary
+	var levelMinimum, defaultMinimum *Money
+	for i, minimum := range pack.MinimumSalaries {
+		if minimum.Minimum.Currency != salary.Currency {
+			continue
+		}
+		if strings.EqualFold(minimum.Level, contract.Job.Level) {
+			levelMinimum = &pack.MinimumSalaries[i].Minimum
+		} else if minimum.Level == "*" {
+			defaultMinimum = &pack.MinimumSalaries[i].Minimum
+		}
+	}
+	if levelMinimum == nil {
+		levelMinimum = defaultMinimum
+	}
+	// Minimums are not converted. A pack with no minimum in the salary currency, such as the rules of the other country
+	// of a cross-border contract, doesn't constrain the salary.
+	if levelMinimum == nil {
+		return ""
+	}
+	if salary.MinorUnits < levelMinimum.MinorUnits {
+		return fmt.Sprintf("the salary of %s is below the minimum of %s for the %s level", salary, *levelMinimum, contract.Job.Level)
+	}
+	return ""
+}
+
+func checkContractLength(pack *RulePack, contract *Contract) string {
+	if pack.MaxContractMonths == 0 {
+		return ""
+	}
+	startDate, err := time.Parse(dateFormat, contract.StartDate)
+	if err != nil {
+		return ""
+	}
+	endDate, err := time.Parse(dateFormat, contract.EndDate)
+	if err != nil {
+		return ""
+	}
+	if endDate.After(startDate.AddDate(0, pack.MaxContractMonths, 0)) {
+		return fmt.Sprintf("the contract runs from %s to %s, longer than the maximum of %d months", contract.StartDate, contract.EndDate, pack.MaxContractMonths)
+	}
+	return ""
+}
+
+func checkMinimumHousing(pack *RulePack, contract *Contract) string {
+	if pack.MinimumHousing == nil {
+		return ""
+	}
+	housing := contract.Benefits.Housing
+	if housing.MinorUnits == 0 {
+		return "housing is mandatory"
+	}
+	// Like the salary, an amount in another currency than the minimum's is not compared with it.
+	if housing.Currency == pack.MinimumHousing.Currency && housing.MinorUnits < pack.MinimumHousing.MinorUnits {
+		return fmt.Sprintf("the housing of %s is below the minimum of %s", housing, *pack.MinimumHousing)
+	}
+	return ""
+}
+
+func checkMinimumLeave(pack *RulePack, contract *Contract) string {
+	if contract.Benefits.AnnualLeave.Days < pack.MinimumLeaveDays {
+		return fmt.Sprintf("the annual leave of %d days is below the minimum of %d days", contract.Benefits.AnnualLeave.Days, pack.MinimumLeaveDays)
+	}
+	return ""
+}
+
+func checkProbation(pack *RulePack, contract *Contract) string {
+	if pack.MaxProbationMonths > 0 && contract.Job.ProbationMonths > pack.MaxProbationMonths {
+		return fmt.Sprintf("the probation of %d months is longer than the maximum of %d months", contract.Job.ProbationMonths, pack.MaxProbationMonths)
+	}
+	return ""
+}
+
+// validate will return an error if the rule pack can't be enforced.
+func (p *RulePack) validate() error {
+	if strings.TrimSpace(p.Country) == "" {
+		return fmt.Errorf("the country of the rule pack can not be empty")
+	}
+	if p.MaxContractMonths < 0 || p.MinimumLeaveDays < 0 || p.MaxProbationMonths < 0 {
+		return fmt.Errorf("the limits of the rule pack can not be negative")
+	}
+	levels := map[string]bool{}
+	for _, minimum := range p.MinimumSalaries {
+		level := strings.ToLower(strings.TrimSpace(minimum.Level))
+		if level == "" {
+			return fmt.Errorf("every minimum salary needs a job level, or * for every level")
+		}
+		if levels[level+" "+minimum.Minimum.Currency] {
+			return fmt.Errorf("the job level %s has more than one minimum salary in %s", minimum.Level, minimum.Minimum.Currency)
+		}
+		levels[level+" "+minimum.Minimum.Currency] = true
+		if err := checkCurrency(minimum.Minimum.Currency); err != nil {
+			return err
+		}
+		if err := minimum.Minimum.validate("minimum salary", minimum.Minimum.Currency); err != nil {
+			return err
+		}
+	}
+	if p.MinimumHousing != nil {
+		if err := checkCurrency(p.MinimumHousing.Currency); err != nil {
+			return err
+		}
+		if err := p.MinimumHousing.validate("minimum housing", p.MinimumHousing.Currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rulePackKey(ctx contractapi.TransactionContextInterface, country string, version int) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(rulePackKeyType, []string{country, strconv.Itoa(version)})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", rulePackKeyType, err)
+	}
+	return key, nil
+}
+
+// readRulePack returns the given version of the country's rule pack, or the latest one if the version is 0.
+// Will return an empty pack at version 0 if the country has no rules yet.
+func readRulePack(ctx contractapi.TransactionContextInterface, country string, version int) (*RulePack, error) {
+	if version > 0 {
+		key, err := rulePackKey(ctx, country, version)
+		if err != nil {
+			return nil, err
+		}
+		packJSON, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if packJSON == nil {
+			return nil, fmt.Errorf("the rule pack of %s has no version %d", country, version)
+		}
+		var pack RulePack
+		if err := json.Unmarshal(packJSON, &pack); err != nil {
+			return nil, err
+		}
+		return &pack, nil
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(rulePackKeyType, []string{country})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	latest := RulePack{Country: country, MinimumSalaries: []LevelMinimum{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var pack RulePack
+		if err := json.Unmarshal(queryResponse.Value, &pack); err != nil {
+			return nil, err
+		}
+		if pack.Version > latest.Version {
+			latest = pack
+		}
+	}
+	return &latest, nil
+}
+
+// checkCompliance runs complianceRules against the latest rule packs of the employer's and the employee's country.
+// The contract must hold its private details. Returns the versions it was checked against,
+// or a ComplianceError with every violation. Countries without rules are skipped.
+func checkCompliance(ctx contractapi.TransactionContextInterface, contract *Contract) ([]RulePackVersion, error) {
+	countries := []string{contract.Employer.Country}
+	if contract.Employee.Country != contract.Employer.Country {
+		countries = append(countries, contract.Employee.Country)
+	}
+
+	versions := []RulePackVersion{}
+	violations := []ComplianceViolation{}
+	for _, country := range countries {
+		pack, err := readRulePack(ctx, country, 0)
+		if err != nil {
+			return nil, err
+		}
+		if pack.Version == 0 {
+			continue
+		}
+		versions = append(versions, RulePackVersion{Country: country, Version: pack.Version})
+		for _, rule := range complianceRules {
+			if message := rule.check(pack, contract); message != "" {
+				violations = append(violations, ComplianceViolation{RuleID: rule.ID, Country: country, Version: pack.Version, Message: message})
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return nil, &ComplianceError{Violations: violations}
+	}
+	return versions, nil
+}
+
+/*
+* This method will publish a new version of a country's rule pack. Contracts created from now on must meet it.
+* @Param jsonString is the RulePack. Its Version, Updated by, and Updated date are set by the chaincode.
+* @Param ExpectedVersion is the version the regulator changed, 0 for the first one. The update is refused if someone else published a version since.
+* Only regulators of the country's MSP can run it.
+ */
+func (s *SmartContract) SetRulePack(ctx contractapi.TransactionContextInterface, jsonString string, ExpectedVersion int) (bool, error) {
+	var pack RulePack
+	if err := json.Unmarshal([]byte(strings.ReplaceAll(jsonString, "'", "\"")), &pack); err != nil {
+		return false, fmt.Errorf("Error Unmarshaling JSON: %s, \n %s", err, jsonString)
+	}
+	if err := pack.validate(); err != nil {
+		return false, err
+	}
+
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	if caller.Role != RoleRegulator || caller.MSPID != countryMSP(pack.Country) {
+		return false, fmt.Errorf("access denied: caller with role %s from %s can not call SetRulePack for %s", caller.Role, caller.MSPID, pack.Country)
+	}
+
+	current, err := readRulePack(ctx, pack.Country, 0)
+	if err != nil {
+		return false, err
+	}
+	if err := checkRevision("rule pack", pack.Country, current.Version, ExpectedVersion); err != nil {
+		return false, err
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if pack.MinimumSalaries == nil {
+		pack.MinimumSalaries = []LevelMinimum{}
+	}
+	pack.Version = current.Version + 1
+	pack.UpdatedBy = caller.MSPID
+	pack.UpdatedDate = currentDate.Format(dateFormat)
+
+	key, err := rulePackKey(ctx, pack.Country, pack.Version)
+	if err != nil {
+		return false, err
+	}
+	packJSON, err := json.Marshal(pack)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.GetStub().PutState(key, packJSON); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will return a version of the country's rule pack, so anyone can check the rules a contract was held to.
+* @Param Version is the version to return, or 0 for the latest one. A country without rules returns an empty pack at version 0.
+ */
+func (s *SmartContract) GetRulePack(ctx contractapi.TransactionContextInterface, Country string, Version int) (*RulePack, error) {
+	// Any enrolled identity with a valid role can read the rules.
+	if _, err := getCaller(ctx); err != nil {
+		return nil, err
+	}
+	if Version < 0 {
+		return nil, fmt.Errorf("the version can not be negative")
+	}
+	return readRulePack(ctx, Country, Version)
+}
@@ -0,0 +1,151 @@
+package chaincode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRulePackValidate(t *testing.T) {
+	sar := func(minorUnits int64) Money { return Money{MinorUnits: minorUnits, Currency: "SAR"} }
+	housing := sar(100000)
+
+	tests := []struct {
+		name    string
+		pack    RulePack
+		wantErr string
+	}{
+		{"empty", RulePack{Country: "Saudi Arabia"}, ""},
+		{"every rule", RulePack{Country: "Saudi Arabia", MinimumSalaries: []LevelMinimum{{"Senior", sar(800000)}, {"*", sar(400000)},
+			{"senior", Money{MinorUnits: 200000, Currency: "USD"}}}, MaxContractMonths: 36, MinimumHousing: &housing, MinimumLeaveDays: 21, MaxProbationMonths: 3}, ""},
+		{"no country", RulePack{Country: " "}, "the country of the rule pack can not be empty"},
+		{"negative limit", RulePack{Country: "Saudi Arabia", MinimumLeaveDays: -1}, "the limits of the rule pack can not be negative"},
+		{"no level", RulePack{Country: "Saudi Arabia", MinimumSalaries: []LevelMinimum{{"", sar(400000)}}}, "every minimum salary needs a job level"},
+		{"level twice in a currency", RulePack{Country: "Saudi Arabia", MinimumSalaries: []LevelMinimum{{"Senior", sar(800000)}, {"senior ", sar(900000)}}},
+			"the job level senior  has more than one minimum salary in SAR"},
+		{"unknown currency", RulePack{Country: "Saudi Arabia", MinimumSalaries: []LevelMinimum{{"Senior", Money{MinorUnits: 1, Currency: "SR"}}}}, "unknown currency: SR"},
+		{"negative minimum", RulePack{Country: "Saudi Arabia", MinimumSalaries: []LevelMinimum{{"Senior", sar(-1)}}}, "the minimum salary can not be negative"},
+		{"housing in an unknown currency", RulePack{Country: "Saudi Arabia", MinimumHousing: &Money{MinorUnits: 1}}, "unknown currency: "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wantError(t, test.pack.validate(), test.wantErr)
+		})
+	}
+}
+
+func TestComplianceRules(t *testing.T) {
+	sar := func(minorUnits int64) Money { return Money{MinorUnits: minorUnits, Currency: "SAR"} }
+	contract := func(change func(c *Contract)) *Contract {
+		c := &Contract{StartDate: "01/02/2026", EndDate: "05/05/2028", Job: Job{Level: "Senior", ProbationMonths: 3},
+			Benefits: Benefits{Currency: "SAR", Salary: sar(1000000), Housing: sar(200000), AnnualLeave: AnnualLeave{Days: 30}}}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+	housing := sar(150000)
+	pack := &RulePack{Country: "Saudi Arabia", MinimumSalaries: []LevelMinimum{{"senior", sar(800000)}, {"*", sar(400000)}, {"Junior", Money{MinorUnits: 150000, Currency: "USD"}}},
+		MaxContractMonths: 29, MinimumHousing: &housing, MinimumLeaveDays: 21, MaxProbationMonths: 3}
+
+	tests := []struct {
+		name     string
+		pack     *RulePack
+		contract *Contract
+		want     string
+	}{
+		{"min-salary", pack, contract(nil), ""},
+		{"min-salary", pack, contract(func(c *Contract) { c.Benefits.Salary = sar(799999) }),
+			"the salary of 7999.99 SAR is below the minimum of 8000.00 SAR for the Senior level"},
+		{"min-salary", pack, contract(func(c *Contract) { c.Job.Level = "Lead"; c.Benefits.Salary = sar(400000) }), ""},
+		{"min-salary", pack, contract(func(c *Contract) { c.Job.Level = "Lead"; c.Benefits.Salary = sar(300000) }),
+			"the salary of 3000.00 SAR is below the minimum of 4000.00 SAR for the Lead level"},
+		{"min-salary", pack, contract(func(c *Contract) { c.Job.Level = "Junior"; c.Benefits.Salary = sar(300000) }),
+			"the salary of 3000.00 SAR is below the minimum of 4000.00 SAR for the Junior level"},
+		{"min-salary", pack, contract(func(c *Contract) { c.Benefits.Salary = Money{MinorUnits: 100, Currency: "INR"} }), ""},
+		{"min-salary", &RulePack{}, contract(nil), ""},
+		{"max-contract-length", pack, contract(nil), ""},
+		{"max-contract-length", pack, contract(func(c *Contract) { c.EndDate = "06/03/2028" }),
+			"the contract runs from 01/02/2026 to 06/03/2028, longer than the maximum of 29 months"},
+		{"max-contract-length", &RulePack{}, contract(func(c *Contract) { c.EndDate = "05/06/2040" }), ""},
+		{"min-housing", pack, contract(nil), ""},
+		{"min-housing", pack, contract(func(c *Contract) { c.Benefits.Housing = sar(0) }), "housing is mandatory"},
+		{"min-housing", pack, contract(func(c *Contract) { c.Benefits.Housing = sar(100000) }),
+			"the housing of 1000.00 SAR is below the minimum of 1500.00 SAR"},
+		{"min-housing", pack, contract(func(c *Contract) { c.Benefits.Housing = Money{MinorUnits: 100, Currency: "USD"} }), ""},
+		{"min-housing", pack, contract(func(c *Contract) { c.Benefits.Housing = Money{Currency: "USD"} }), "housing is mandatory"},
+		{"min-housing", &RulePack{}, contract(func(c *Contract) { c.Benefits.Housing = sar(0) }), ""},
+		{"min-annual-leave", pack, contract(func(c *Contract) { c.Benefits.AnnualLeave.Days = 21 }), ""},
+		{"min-annual-leave", pack, contract(func(c *Contract) { c.Benefits.AnnualLeave.Days = 20 }),
+			"the annual leave of 20 days is below the minimum of 21 days"},
+		{"max-probation", pack, contract(nil), ""},
+		{"max-probation", pack, contract(func(c *Contract) { c.Job.ProbationMonths = 4 }),
+			"the probation of 4 months is longer than the maximum of 3 months"},
+		{"max-probation", &RulePack{}, contract(func(c *Contract) { c.Job.ProbationMonths = 12 }), ""},
+	}
+	checks := map[string]func(pack *RulePack, contract *Contract) string{}
+	for _, rule := range complianceRules {
+		checks[rule.ID] = rule.check
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := checks[test.name](test.pack, test.contract); got != test.want {
+				t.Fatalf("want %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestComplianceOnLedger(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+
+	_, err := l.contract.SetRulePack(l.as(testEmployer), `{'Country': 'Saudi Arabia'}`, 0)
+	wantError(t, err, "access denied")
+	_, err = l.contract.SetRulePack(l.as(testRegulatorA), `{'Country': 'India'}`, 0)
+	wantError(t, err, "access denied")
+
+	strict := `{'Country': 'Saudi Arabia','Minimum salaries': [{'Level': 'Senior','Minimum': {'Minor units': 1200000,'Currency': 'SAR'}}],
+'Max contract months': 24}`
+	l.ok(l.contract.SetRulePack(l.as(testRegulatorA), strict, 0))
+	_, err = l.contract.SetRulePack(l.as(testRegulatorA), strict, 0)
+	var conflict *RevisionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("want a revision conflict, got %v", err)
+	}
+
+	_, err = l.contract.HandleAddContract(l.as(testEmployer), testContractJSON)
+	var compliance *ComplianceError
+	if !errors.As(err, &compliance) {
+		t.Fatalf("want a compliance error, got %v", err)
+	}
+	ruleIDs := []string{}
+	for _, violation := range compliance.Violations {
+		if violation.Country != "Saudi Arabia" || violation.Version != 1 {
+			t.Fatalf("want violations of the Saudi Arabia rules v1, got %+v", violation)
+		}
+		ruleIDs = append(ruleIDs, violation.RuleID)
+	}
+	if want := []string{"min-salary", "max-contract-length"}; !reflect.DeepEqual(ruleIDs, want) {
+		t.Fatalf("want the violations %v, got %v", want, ruleIDs)
+	}
+
+	l.ok(l.contract.SetRulePack(l.as(testRegulatorA), `{'Country': 'Saudi Arabia','Minimum salaries': [{'Level': '*','Minimum': {'Minor units': 800000,'Currency': 'SAR'}}],
+'Max contract months': 36}`, 1))
+	l.activeContract("11930", "01/02/2026", "05/05/2028")
+	if want := []RulePackVersion{{"Saudi Arabia", 2}}; !reflect.DeepEqual(l.read("11930").Compliance, want) {
+		t.Fatalf("want the contract checked against %v, got %v", want, l.read("11930").Compliance)
+	}
+
+	pack, err := l.contract.GetRulePack(l.as(testEmployee), "Saudi Arabia", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pack.Version != 1 || pack.MaxContractMonths != 24 || pack.UpdatedBy != "CountryAMSP" || pack.UpdatedDate != "01/01/2026" {
+		t.Fatalf("want version 1 of the rules, got %+v", pack)
+	}
+
+	l.setDate("03/01/2028")
+	_, err = l.contract.ExtendContract(l.as(testEmployer), "11930", "05/05/2029", l.read("11930").Revision)
+	wantError(t, err, "[Saudi Arabia max-contract-length v2] the contract runs from 01/02/2026 to 05/05/2029, longer than the maximum of 36 months")
+	l.ok(l.contract.ExtendContract(l.as(testEmployer), "11930", "01/02/2029", l.read("11930").Revision))
+}
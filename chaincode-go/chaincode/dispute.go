@@ -0,0 +1,381 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Disputes are stored as their own records under this composite key object type, so issuing or answering a dispute
+// doesn't rewrite the whole contract. The Disputes field of the contract record stays empty.
+const disputeKeyType = "contract~dispute" // contract ID, dispute ID
+
+// DisputeCategory: what the dispute is about, so authorities can sort complaints without reading every one.
+type DisputeCategory string
+
+const (
+	CategoryWages       DisputeCategory = "wages"
+	CategoryHousing     DisputeCategory = "housing"
+	CategoryLeave       DisputeCategory = "leave"
+	CategorySafety      DisputeCategory = "safety"
+	CategoryTermination DisputeCategory = "termination"
+	CategoryOther       DisputeCategory = "other"
+)
+
+// disputeSLAs is the number of days the employer has to respond to a dispute of each category.
+var disputeSLAs = map[DisputeCategory]int{
+	CategorySafety:      2,
+	CategoryWages:       7,
+	CategoryTermination: 7,
+	CategoryHousing:     14,
+	CategoryLeave:       14,
+	CategoryOther:       30,
+}
+
+// DisputeSeverity: how serious the employee considers the dispute.
+type DisputeSeverity string
+
+const (
+	SeverityLow      DisputeSeverity = "low"
+	SeverityMedium   DisputeSeverity = "medium"
+	SeverityHigh     DisputeSeverity = "high"
+	SeverityCritical DisputeSeverity = "critical"
+)
+
+var disputeSeverities = []DisputeSeverity{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// parseCategory will return an error if the given category is not one of the DisputeCategory values. Case is ignored.
+func parseCategory(category string) (DisputeCategory, error) {
+	parsed := DisputeCategory(strings.ToLower(strings.TrimSpace(category)))
+	if _, ok := disputeSLAs[parsed]; !ok {
+		return "", fmt.Errorf("unknown dispute category: %s. It can only be wages, housing, leave, safety, termination, or other", category)
+	}
+	return parsed, nil
+}
+
+// parseSeverity will return an error if the given severity is not one of the DisputeSeverity values. Case is ignored.
+func parseSeverity(severity string) (DisputeSeverity, error) {
+	parsed := DisputeSeverity(strings.ToLower(strings.TrimSpace(severity)))
+	for _, known := range disputeSeverities {
+		if parsed == known {
+			return parsed, nil
+		}
+	}
+	return "", fmt.Errorf("unknown dispute severity: %s. It can only be low, medium, high, or critical", severity)
+}
+
+// The format of Dispute.ResponseDueKey. Unlike dateFormat, it sorts in date order.
+const dueKeyFormat = "2006-01-02"
+
+// responseDueDate returns the last day the employer can respond to a dispute of the given category issued on the given date.
+func responseDueDate(category DisputeCategory, issued time.Time) string {
+	return issued.AddDate(0, 0, disputeSLAs[category]).Format(dateFormat)
+}
+
+// isOverdue will return true if the dispute is still Active, its response due date has passed,
+// and the employer did not respond on or before that date. Disputes issued before categories were added have no due date.
+func isOverdue(dispute Dispute, now time.Time) bool {
+	if dispute.Status != "Active" {
+		return false
+	}
+	dueDate, err := time.Parse(dateFormat, dispute.ResponseDueDate)
+	if err != nil {
+		return false
+	}
+	// The employer can still respond on the due date.
+	if now.Before(dueDate.AddDate(0, 0, 1)) {
+		return false
+	}
+	for _, response := range dispute.Responses {
+		responseDate, err := time.Parse(dateFormat, response.LastUpdatedDate)
+		if err == nil && !responseDate.After(dueDate) {
+			return false
+		}
+	}
+	return true
+}
+
+func disputeKey(ctx contractapi.TransactionContextInterface, contractID string, disputeID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(disputeKeyType, []string{contractID, disputeID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", disputeKeyType, err)
+	}
+	return key, nil
+}
+
+// putDispute increases the dispute revision and writes the dispute record of the given contract.
+// It also sets the response due key, since MM/DD/YYYY dates don't sort and GetOverdueDisputes compares due dates in CouchDB.
+func putDispute(ctx contractapi.TransactionContextInterface, contractID string, dispute *Dispute) error {
+	key, err := disputeKey(ctx, contractID, dispute.ID)
+	if err != nil {
+		return err
+	}
+	dispute.ContractID = contractID
+	dispute.Revision++
+	dispute.ResponseDueKey = ""
+	if dueDate, err := time.Parse(dateFormat, dispute.ResponseDueDate); err == nil {
+		dispute.ResponseDueKey = dueDate.Format(dueKeyFormat)
+	}
+	disputeJSON, err := json.Marshal(dispute)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, disputeJSON)
+}
+
+// getDispute returns the dispute record with the given ID. Will return an error if the contract has no such dispute.
+func getDispute(ctx contractapi.TransactionContextInterface, contractID string, disputeID string) (*Dispute, error) {
+	key, err := disputeKey(ctx, contractID, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	disputeJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if disputeJSON == nil {
+		return nil, fmt.Errorf("There is no matching disputes with the given ID: %s ", disputeID)
+	}
+	var dispute Dispute
+	if err := json.Unmarshal(disputeJSON, &dispute); err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// findDispute returns the dispute with the given ID, whether it is still embedded in the contract or has its own record.
+// Call it before migrateDisputes, because a transaction doesn't read its own writes.
+func findDispute(ctx contractapi.TransactionContextInterface, contract *Contract, disputeID string) (*Dispute, error) {
+	for _, dispute := range contract.Disputes {
+		if dispute.ID == disputeID {
+			return &dispute, nil
+		}
+	}
+	return getDispute(ctx, contract.ID, disputeID)
+}
+
+// getContractDispute reads the contract and one of its disputes, checks the caller against accessRules for the transaction,
+// and checks that the dispute is still at the expected revision.
+func getContractDispute(ctx contractapi.TransactionContextInterface, transaction string, ID string, DisputeID string, ExpectedRevision int) (*Contract, *Dispute, error) {
+	contract, err := readContractFor(ctx, transaction, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dispute, err := findDispute(ctx, contract, DisputeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkRevision("dispute", ID+"/"+DisputeID, dispute.Revision, ExpectedRevision); err != nil {
+		return nil, nil, err
+	}
+	if err := migrateDisputes(ctx, contract); err != nil {
+		return nil, nil, err
+	}
+	return contract, dispute, nil
+}
+
+// listDisputes returns every dispute of the contract ordered by ID. Disputes still embedded in
+// contracts written before disputes had their own records are included.
+func listDisputes(ctx contractapi.TransactionContextInterface, contract *Contract) ([]Dispute, error) {
+	disputes := []Dispute{}
+	for _, dispute := range contract.Disputes {
+		dispute.ContractID = contract.ID
+		disputes = append(disputes, dispute)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(disputeKeyType, []string{contract.ID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var dispute Dispute
+		if err := json.Unmarshal(queryResponse.Value, &dispute); err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, dispute)
+	}
+
+	sort.SliceStable(disputes, func(i, j int) bool {
+		return lessID(disputes[i].ID, disputes[j].ID)
+	})
+	return disputes, nil
+}
+
+// lessID compares the IDs the chaincode gives to disputes and amendments. Keys are sorted as strings,
+// so dispute 10 would come before dispute 2 without it. Numbered disputes were issued before newDisputeID, so they come first.
+func lessID(a string, b string) bool {
+	numberA, errA := strconv.Atoi(a)
+	numberB, errB := strconv.Atoi(b)
+	if errA != nil || errB != nil {
+		if (errA == nil) != (errB == nil) {
+			return errA == nil
+		}
+		return a < b
+	}
+	return numberA < numberB
+}
+
+// loadDisputes fills contract.Disputes from the dispute records, so callers get the same contract view as before.
+// Never write a contract back to the world state after loading its disputes.
+func loadDisputes(ctx contractapi.TransactionContextInterface, contract *Contract) error {
+	disputes, err := listDisputes(ctx, contract)
+	if err != nil {
+		return err
+	}
+	contract.Disputes = disputes
+	return nil
+}
+
+// migrateDisputes moves the disputes embedded in a contract written before disputes had their own records
+// into dispute records, and writes the contract back without them. Does nothing for newer contracts.
+func migrateDisputes(ctx contractapi.TransactionContextInterface, contract *Contract) error {
+	if len(contract.Disputes) == 0 {
+		return nil
+	}
+	for i := range contract.Disputes {
+		if err := putDispute(ctx, contract.ID, &contract.Disputes[i]); err != nil {
+			return err
+		}
+	}
+	contract.Disputes = []Dispute{}
+	return putContract(ctx, contract)
+}
+
+// newDisputeID builds the ID of the dispute the transaction issues from its timestamp, so IDs sort by the time they were issued,
+// and its transaction ID, so two disputes never share one. Nothing is read, so parties can issue disputes on one contract at once.
+func newDisputeID(ctx contractapi.TransactionContextInterface) (string, error) {
+	issued, err := TxClock(ctx)
+	if err != nil {
+		return "", err
+	}
+	return issued.Format("20060102T150405Z") + "-" + ctx.GetStub().GetTxID(), nil
+}
+
+/*
+* This method will return the disputes of the contract with the given ID.
+* @Param status can be Active, Escalated, Ruled, or Closed to filter the disputes, or empty to return all of them.
+ */
+func (s *SmartContract) GetDisputes(ctx contractapi.TransactionContextInterface, ID string, status string) ([]Dispute, error) {
+	if status != "" && status != "Active" && status != "Escalated" && status != "Ruled" && status != "Closed" {
+		return nil, fmt.Errorf("unknown dispute status: %s. It can only be Active, Escalated, Ruled, Closed, or empty", status)
+	}
+
+	contract, err := readContractFor(ctx, "GetDisputes", ID)
+	if err != nil {
+		return nil, err
+	}
+
+	disputes, err := listDisputes(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	matching := []Dispute{}
+	for _, dispute := range disputes {
+		if status == "" || dispute.Status == status {
+			matching = append(matching, dispute)
+		}
+	}
+	return matching, nil
+}
+
+// DisputePage: one page of disputes with the bookmark to fetch the next page.
+type DisputePage struct {
+	Records             []Dispute `json:"Records"`
+	FetchedRecordsCount int       `json:"Fetched records count"` // The number of Records returned.
+	ScannedRecordsCount int       `json:"Scanned records count"` // The number of records the page read. Fewer than the page size on the last page.
+	Bookmark            string    `json:"Bookmark"`
+}
+
+/*
+* This method will return the Active disputes whose response due date has passed without a response from the employer.
+* Authorities get the disputes of their countries, and employers and employees get the disputes of their own contracts.
+* It selects on the status and the due date through indexOverdueDisputes, so it needs the peers to run CouchDB.
+* @Param pageSize is the maximum number of disputes read by one call.
+* @Param bookmark is empty for the first page, and then the Bookmark returned by the previous one.
+* A page drops the disputes the caller can not read and the ones answered in time, so keep fetching until Scanned records count is less than the page size.
+ */
+func (s *SmartContract) GetOverdueDisputes(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*DisputePage, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize < 1 {
+		return nil, fmt.Errorf("the page size must be at least 1")
+	}
+	now, err := s.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The employer can still respond on the due date, so only due dates before today are selected.
+	query, err := json.Marshal(map[string]interface{}{
+		"selector": map[string]interface{}{
+			"Status":           "Active",
+			"Response due key": map[string]interface{}{"$lt": now.Format(dueKeyFormat)},
+		},
+		"use_index": []string{"_design/indexOverdueDisputesDoc", "indexOverdueDisputes"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(query), int32(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run the query: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	// Many disputes share a contract, so each contract is only read and checked once.
+	readable := map[string]bool{}
+	overdue := []Dispute{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var dispute Dispute
+		if err := json.Unmarshal(queryResponse.Value, &dispute); err != nil {
+			return nil, err
+		}
+		if !isOverdue(dispute, now) {
+			continue
+		}
+
+		canRead, checked := readable[dispute.ContractID]
+		if !checked {
+			contractJSON, err := ctx.GetStub().GetState(dispute.ContractID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read from world state: %v", err)
+			}
+			if contractJSON != nil {
+				var contract Contract
+				if err := json.Unmarshal(contractJSON, &contract); err != nil {
+					return nil, err
+				}
+				canRead = caller.can("GetDisputes", &contract)
+			}
+			readable[dispute.ContractID] = canRead
+		}
+		if canRead {
+			overdue = append(overdue, dispute)
+		}
+	}
+
+	return &DisputePage{
+		Records:             overdue,
+		FetchedRecordsCount: len(overdue),
+		ScannedRecordsCount: int(metadata.FetchedRecordsCount),
+		Bookmark:            metadata.Bookmark,
+	}, nil
+}
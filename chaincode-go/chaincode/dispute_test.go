@@ -0,0 +1,276 @@
+package chaincode
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestResponseDueDate(t *testing.T) {
+	issued := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		category DisputeCategory
+		want     string
+	}{
+		{CategorySafety, "03/01/2026"},
+		{CategoryWages, "03/06/2026"},
+		{CategoryTermination, "03/06/2026"},
+		{CategoryHousing, "03/13/2026"},
+		{CategoryLeave, "03/13/2026"},
+		{CategoryOther, "03/29/2026"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.category), func(t *testing.T) {
+			if got := responseDueDate(test.category, issued); got != test.want {
+				t.Fatalf("want %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestParseCategoryAndSeverity(t *testing.T) {
+	tests := []struct {
+		name         string
+		category     string
+		severity     string
+		wantCategory DisputeCategory
+		wantSeverity DisputeSeverity
+		wantErr      string
+	}{
+		{"lower case", "wages", "high", CategoryWages, SeverityHigh, ""},
+		{"any case and spaces", " Safety ", "CRITICAL", CategorySafety, SeverityCritical, ""},
+		{"unknown category", "salary", "low", "", SeverityLow, "unknown dispute category: salary"},
+		{"unknown severity", "other", "urgent", CategoryOther, "", "unknown dispute severity: urgent"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			category, categoryErr := parseCategory(test.category)
+			severity, severityErr := parseSeverity(test.severity)
+			err := categoryErr
+			if err == nil {
+				err = severityErr
+			}
+			wantError(t, err, test.wantErr)
+			if category != test.wantCategory || severity != test.wantSeverity {
+				t.Fatalf("want %s and %s, got %s and %s", test.wantCategory, test.wantSeverity, category, severity)
+			}
+		})
+	}
+}
+
+func TestIsOverdue(t *testing.T) {
+	dispute := func(status string, responses ...string) Dispute {
+		d := Dispute{Status: status, ResponseDueDate: "03/06/2026", Responses: []Response{}}
+		for _, date := range responses {
+			d.Responses = append(d.Responses, Response{LastUpdatedDate: date})
+		}
+		return d
+	}
+
+	tests := []struct {
+		name    string
+		dispute Dispute
+		today   string
+		want    bool
+	}{
+		{"before the due date", dispute("Active"), "03/05/2026", false},
+		{"on the due date", dispute("Active"), "03/06/2026", false},
+		{"the day after the due date", dispute("Active"), "03/07/2026", true},
+		{"answered on the due date", dispute("Active", "03/06/2026"), "03/20/2026", false},
+		{"answered late", dispute("Active", "03/10/2026"), "03/20/2026", true},
+		{"closed", dispute("Closed"), "03/20/2026", false},
+		{"escalated", dispute("Escalated"), "03/20/2026", false},
+		{"issued before categories", Dispute{Status: "Active"}, "03/20/2026", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			today, err := time.Parse(dateFormat, test.today)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := isOverdue(test.dispute, today); got != test.want {
+				t.Fatalf("want %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestDisputeDeadlines(t *testing.T) {
+	tests := []struct {
+		name        string
+		category    string
+		respondOn   string // Empty if the employer does not respond.
+		checkOn     string
+		wantDueDate string
+		wantOverdue bool
+	}{
+		{"safety, not answered", "safety", "", "03/04/2026", "03/03/2026", true},
+		{"safety, answered in time", "safety", "03/03/2026", "03/04/2026", "03/03/2026", false},
+		{"wages, not due yet", "wages", "", "03/08/2026", "03/08/2026", false},
+		{"other, answered late", "other", "04/01/2026", "04/02/2026", "03/31/2026", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLedger(t, "01/01/2026")
+			l.activeContract("11930", "01/02/2026", "05/05/2028")
+			l.setDate("03/01/2026")
+			revision := l.read("11930").Revision
+			l.ok(l.contract.IssueDispute(l.as(testEmployee), "11930", test.category, "high", "The salary of February is late", revision))
+
+			disputes, err := l.contract.GetDisputes(l.as(testEmployer), "11930", "Active")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(disputes) != 1 || disputes[0].ResponseDueDate != test.wantDueDate {
+				t.Fatalf("want one dispute due on %s, got %+v", test.wantDueDate, disputes)
+			}
+			if test.respondOn != "" {
+				l.setDate(test.respondOn)
+				l.ok(l.contract.RespondToDispute(l.as(testEmployer), "11930", disputes[0].ID, "Paid today", disputes[0].Revision))
+			}
+
+			l.setDate(test.checkOn)
+			overdue, err := l.contract.GetOverdueDisputes(l.as(testAuthorityB), 10, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(overdue.Records) == 1; got != test.wantOverdue {
+				t.Fatalf("want overdue %v, got %+v", test.wantOverdue, overdue.Records)
+			}
+		})
+	}
+}
+
+func TestGetOverdueDisputesPages(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+	l.activeContract("1", "01/02/2026", "05/05/2028")
+	l.activeContract("2", "01/02/2026", "05/05/2028")
+	l.setDate("03/01/2026")
+	for _, dispute := range []struct{ contractID, category string }{
+		{"1", "safety"}, // Due on 03/03/2026.
+		{"1", "wages"},  // Due on 03/08/2026.
+		{"2", "safety"},
+		{"2", "other"}, // Due on 03/31/2026.
+		{"2", "wages"}, // Answered in time below.
+	} {
+		revision := l.read(dispute.contractID).Revision
+		l.ok(l.contract.IssueDispute(l.as(testEmployee), dispute.contractID, dispute.category, "high", "Not paid", revision))
+	}
+	disputes, err := l.contract.GetDisputes(l.as(testEmployer), "2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var answered Dispute
+	for _, dispute := range disputes {
+		if dispute.Category == CategoryWages {
+			answered = dispute
+		}
+	}
+	l.ok(l.contract.RespondToDispute(l.as(testEmployer), "2", answered.ID, "Paid today", answered.Revision))
+
+	tests := []struct {
+		name     string
+		caller   testIdentity
+		pageSize int
+		want     int
+		wantErr  string
+	}{
+		{"one page", testAuthorityA, 10, 3, ""},
+		{"a page per dispute", testAuthorityB, 1, 3, ""},
+		{"pages of two", testEmployer, 2, 3, ""},
+		{"another employee", testOtherWorker, 1, 0, ""},
+		{"empty page", testAuthorityA, 0, 0, "the page size must be at least 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l.setDate("03/09/2026")
+			overdue := []Dispute{}
+			bookmark := ""
+			for pages := 0; ; pages++ {
+				page, err := l.contract.GetOverdueDisputes(l.as(test.caller), test.pageSize, bookmark)
+				wantError(t, err, test.wantErr)
+				if err != nil {
+					return
+				}
+				if page.ScannedRecordsCount > test.pageSize || pages > 10 {
+					t.Fatalf("read %d disputes on a page of %d", page.ScannedRecordsCount, test.pageSize)
+				}
+				overdue = append(overdue, page.Records...)
+				if page.ScannedRecordsCount < test.pageSize || page.Bookmark == "" {
+					break
+				}
+				bookmark = page.Bookmark
+			}
+			if len(overdue) != test.want {
+				t.Fatalf("want %d overdue disputes, got %+v", test.want, overdue)
+			}
+			for _, dispute := range overdue {
+				if dispute.ID == answered.ID || dispute.Category == CategoryOther {
+					t.Fatalf("the dispute %s is not overdue", dispute.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestIssueDisputeIDs(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+	l.activeContract("11930", "01/02/2026", "05/05/2028")
+	l.setDate("03/01/2026")
+
+	issuedAt := []time.Time{
+		time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC), // Issued in the same second as the first one.
+		time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+	}
+	want := []string{}
+	revision := l.read("11930").Revision // A new dispute does not change the contract.
+	for _, issued := range issuedAt {
+		ctx := l.as(testEmployee)
+		l.stub.TxTimestamp = &timestamp.Timestamp{Seconds: issued.Unix()}
+		rangeQueries := l.stub.rangeQueries
+		l.ok(l.contract.IssueDispute(ctx, "11930", "wages", "high", "The salary of February is late", revision))
+		if l.stub.rangeQueries != rangeQueries {
+			t.Fatalf("IssueDispute ran %d range queries, which conflict with concurrent disputes", l.stub.rangeQueries-rangeQueries)
+		}
+		want = append(want, issued.Format("20060102T150405Z")+"-"+l.stub.TxID)
+	}
+	sort.Strings(want[:2]) // The same second is ordered by transaction ID.
+
+	disputes, err := l.contract.GetDisputes(l.as(testEmployer), "11930", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, dispute := range disputes {
+		got = append(got, dispute.ID)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want the disputes %v, got %v", want, got)
+	}
+}
+
+func TestLessID(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2", "10", true},
+		{"10", "2", false},
+		{"9", "20260301T093000Z-tx1", true},
+		{"20260301T093000Z-tx1", "9", false},
+		{"20260301T093000Z-tx1", "20260302T080000Z-tx0", true},
+		{"20260301T093000Z-tx2", "20260301T093000Z-tx1", false},
+	}
+	for _, test := range tests {
+		t.Run(test.a+" < "+test.b, func(t *testing.T) {
+			if got := lessID(test.a, test.b); got != test.want {
+				t.Fatalf("want %v, got %v", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package chaincode
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Documents are stored as their own records under this composite key object type. Only the hash and the location
+// of a file are on the ledger, the file itself stays off-chain.
+const documentKeyType = "contract~document" // contract ID, document ID
+
+// DocumentType: what a document attached to a contract is.
+type DocumentType string
+
+const (
+	DocumentOfferLetter DocumentType = "offer letter"
+	DocumentPassport    DocumentType = "passport"
+	DocumentPayslip     DocumentType = "payslip"
+	DocumentVisa        DocumentType = "visa"
+	DocumentOther       DocumentType = "other"
+)
+
+var documentTypes = []DocumentType{DocumentOfferLetter, DocumentPassport, DocumentPayslip, DocumentVisa, DocumentOther}
+
+// Document: an off-chain file anchored to a contract by its SHA-256 hash.
+// Anyone holding the file can check it is the one that was attached with VerifyDocument.
+type Document struct {
+	ID           string       `json:"ID"`
+	ContractID   string       `json:"Contract ID"`
+	Type         DocumentType `json:"Type"`    // One of documentTypes.
+	SHA256       string       `json:"SHA-256"` // The hash of the file, as 64 lower case hex digits.
+	URI          string       `json:"URI"`     // Where the file is kept, for example in a content-addressed store.
+	AttachedBy   Caller       `json:"Attached by"`
+	AttachedDate string       `json:"Attached date"`
+}
+
+// parseDocumentType will return an error if the given type is not one of the DocumentType values. Case is ignored.
+func parseDocumentType(docType string) (DocumentType, error) {
+	parsed := DocumentType(strings.ToLower(strings.TrimSpace(docType)))
+	for _, known := range documentTypes {
+		if parsed == known {
+			return parsed, nil
+		}
+	}
+	return "", fmt.Errorf("unknown document type: %s. It can only be offer letter, passport, payslip, visa, or other", docType)
+}
+
+// parseSHA256 returns the hash in lower case. Will return an error if it is not 64 hex digits.
+func parseSHA256(hash string) (string, error) {
+	parsed := strings.ToLower(strings.TrimSpace(hash))
+	if decoded, err := hex.DecodeString(parsed); err != nil || len(decoded) != 32 {
+		return "", fmt.Errorf("the SHA-256 hash must be 64 hex digits: %s", hash)
+	}
+	return parsed, nil
+}
+
+func documentKey(ctx contractapi.TransactionContextInterface, contractID string, documentID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(documentKeyType, []string{contractID, documentID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", documentKeyType, err)
+	}
+	return key, nil
+}
+
+// getDocument returns the document with the given ID. Will return an error if the contract has no such document.
+func getDocument(ctx contractapi.TransactionContextInterface, contractID string, documentID string) (*Document, error) {
+	key, err := documentKey(ctx, contractID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	documentJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if documentJSON == nil {
+		return nil, fmt.Errorf("There is no matching documents with the given ID: %s ", documentID)
+	}
+	var document Document
+	if err := json.Unmarshal(documentJSON, &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// listDocuments returns every document of the contract ordered by ID.
+func listDocuments(ctx contractapi.TransactionContextInterface, contractID string) ([]Document, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(documentKeyType, []string{contractID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	documents := []Document{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var document Document
+		if err := json.Unmarshal(queryResponse.Value, &document); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+
+	sort.SliceStable(documents, func(i, j int) bool {
+		return lessID(documents[i].ID, documents[j].ID)
+	})
+	return documents, nil
+}
+
+/*
+* This method will anchor an off-chain file, such as a signed offer letter, a passport, or a payslip, to the contract.
+* @Param DocType is one of offer letter, passport, payslip, visa, or other.
+* @Param SHA256 is the hash of the file as 64 hex digits. The same file can only be attached once to a contract.
+* @Param URI is where the file can be fetched from. The chaincode doesn't read it.
+ */
+func (s *SmartContract) AttachDocument(ctx contractapi.TransactionContextInterface, ID string, DocType string, SHA256 string, URI string) (bool, error) {
+	docType, err := parseDocumentType(DocType)
+	if err != nil {
+		return false, err
+	}
+	hash, err := parseSHA256(SHA256)
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(URI) == "" {
+		return false, fmt.Errorf("the URI of the document can not be empty")
+	}
+	contract, err := readContractFor(ctx, "AttachDocument", ID)
+	if err != nil {
+		return false, err
+	}
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	documents, err := listDocuments(ctx, contract.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, document := range documents {
+		if document.SHA256 == hash {
+			return false, fmt.Errorf("the file is already attached to the contract as document %s", document.ID)
+		}
+	}
+
+	document := Document{
+		ID:           strconv.Itoa(len(documents)),
+		ContractID:   contract.ID,
+		Type:         docType,
+		SHA256:       hash,
+		URI:          strings.TrimSpace(URI),
+		AttachedBy:   caller,
+		AttachedDate: currentDate.Format(dateFormat),
+	}
+	key, err := documentKey(ctx, document.ContractID, document.ID)
+	if err != nil {
+		return false, err
+	}
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.GetStub().PutState(key, documentJSON); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventDocumentAttached, contract, document.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will return true if the given hash is the one the document was attached with, and false if the file was changed.
+* @Param SHA256 is the hash of the file to check, as 64 hex digits.
+ */
+func (s *SmartContract) VerifyDocument(ctx contractapi.TransactionContextInterface, ID string, DocumentID string, SHA256 string) (bool, error) {
+	hash, err := parseSHA256(SHA256)
+	if err != nil {
+		return false, err
+	}
+	contract, err := readContractFor(ctx, "VerifyDocument", ID)
+	if err != nil {
+		return false, err
+	}
+	document, err := getDocument(ctx, contract.ID, DocumentID)
+	if err != nil {
+		return false, err
+	}
+	return document.SHA256 == hash, nil
+}
+
+// This method will return the documents attached to the contract.
+func (s *SmartContract) GetDocuments(ctx contractapi.TransactionContextInterface, ID string) ([]Document, error) {
+	contract, err := readContractFor(ctx, "GetDocuments", ID)
+	if err != nil {
+		return nil, err
+	}
+	return listDocuments(ctx, contract.ID)
+}
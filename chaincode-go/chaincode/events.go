@@ -0,0 +1,119 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// EventSchemaVersion is the version of ContractEvent. It changes whenever a field is removed or changes meaning,
+// and the matching schema is published in chaincode-go/events.
+const EventSchemaVersion = "1"
+
+// The names events are emitted under. Fabric keeps one event per transaction, so every transaction emits at most one.
+const (
+	EventContractCreated    = "ContractCreated"
+	EventContractUpdated    = "ContractUpdated"
+	EventContractApproved   = "ContractApproved" // An approval was recorded. New status is Active only when it was the last one.
+	EventApprovalWithdrawn  = "ApprovalWithdrawn"
+	EventContractRejected   = "ContractRejected"
+	EventContractSuspended  = "ContractSuspended"
+	EventContractResumed    = "ContractResumed"
+	EventContractCompleted  = "ContractCompleted"
+	EventContractsCompleted = "ContractsCompleted" // One batch of CompleteExpiredContracts. Contract IDs lists the contracts.
+	EventContractTerminated = "ContractTerminated"
+	EventContractExtended   = "ContractExtended"
+	EventContractSigned     = "ContractSigned"
+	EventDisputeIssued      = "DisputeIssued"
+	EventDisputeUpdated     = "DisputeUpdated"
+	EventDisputeResponded   = "DisputeResponded"
+	EventDisputeClosed      = "DisputeClosed"
+	EventDisputeEscalated   = "DisputeEscalated"
+	EventRulingRecorded     = "RulingRecorded"
+	EventRulingEnforced     = "RulingEnforced"
+	EventAmendmentProposed  = "AmendmentProposed"
+	EventAmendmentAccepted  = "AmendmentAccepted" // Accepted, but the effective date has not been reached yet.
+	EventAmendmentRejected  = "AmendmentRejected"
+	EventContractAmended    = "ContractAmended" // An accepted amendment was applied to the contract.
+	EventPaymentRecorded    = "PaymentRecorded"
+	EventPaymentConfirmed   = "PaymentConfirmed"
+	EventPaymentRejected    = "PaymentRejected"
+	EventLeaveRequested     = "LeaveRequested"
+	EventLeaveApproved      = "LeaveApproved"
+	EventLeaveDenied        = "LeaveDenied"
+	EventDocumentAttached   = "DocumentAttached"
+)
+
+// statusEvents maps the status changeStatus moves a contract to, to the event it emits.
+var statusEvents = map[ContractStatus]string{
+	StatusRejected:   EventContractRejected,
+	StatusSuspended:  EventContractSuspended,
+	StatusActive:     EventContractResumed,
+	StatusCompleted:  EventContractCompleted,
+	StatusTerminated: EventContractTerminated,
+}
+
+// ContractEvent: the payload of every contract and dispute event.
+type ContractEvent struct {
+	SchemaVersion string         `json:"Schema version"`
+	Type          string         `json:"Type"` // Same as the event name.
+	ContractID    string         `json:"Contract ID"`
+	OldStatus     ContractStatus `json:"Old status"` // Empty for ContractCreated.
+	NewStatus     ContractStatus `json:"New status"`
+	ContractIDs   []string       `json:"Contract IDs,omitempty"` // Only set by ContractsCompleted, whose Contract ID is empty.
+	DisputeID     string         `json:"Dispute ID,omitempty"`   // Only set by dispute events.
+	RecordID      string         `json:"Record ID,omitempty"`    // Only set by amendment, payment, leave, and document events: the ID of that record.
+	Actor         Caller         `json:"Actor"`
+	TxID          string         `json:"Tx ID"`
+	Timestamp     string         `json:"Timestamp"` // RFC 3339, in UTC.
+}
+
+// emitEvent sets the event of the current transaction. The caller is recorded as the actor.
+func (s *SmartContract) emitEvent(ctx contractapi.TransactionContextInterface, eventType string, contract *Contract, oldStatus ContractStatus, disputeID string) error {
+	return s.setEvent(ctx, ContractEvent{
+		Type:       eventType,
+		ContractID: contract.ID,
+		OldStatus:  oldStatus,
+		NewStatus:  contract.Status,
+		DisputeID:  disputeID,
+	})
+}
+
+// emitRecordEvent sets the event of a transaction that writes an amendment, a payment, a leave request, or a document of the contract.
+// The ID of that record is carried in Record ID, so consumers can read it without scanning the contract.
+func (s *SmartContract) emitRecordEvent(ctx contractapi.TransactionContextInterface, eventType string, contract *Contract, recordID string) error {
+	return s.setEvent(ctx, ContractEvent{
+		Type:       eventType,
+		ContractID: contract.ID,
+		OldStatus:  contract.Status,
+		NewStatus:  contract.Status,
+		RecordID:   recordID,
+	})
+}
+
+// setEvent fills in the schema version, the actor, the transaction ID, and the timestamp of the event, and sets it as the event of the current transaction.
+func (s *SmartContract) setEvent(ctx contractapi.TransactionContextInterface, event ContractEvent) error {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+	now, err := s.now(ctx)
+	if err != nil {
+		return err
+	}
+
+	event.SchemaVersion = EventSchemaVersion
+	event.Actor = caller
+	event.TxID = ctx.GetStub().GetTxID()
+	event.Timestamp = now.Format(time.RFC3339)
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().SetEvent(event.Type, eventJSON); err != nil {
+		return fmt.Errorf("failed to set the %s event: %v", event.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ContractVersion: one version of a contract as it was written by a transaction.
+type ContractVersion struct {
+	TxID      string    `json:"Tx ID"`
+	Timestamp string    `json:"Timestamp"` // RFC 3339, in UTC.
+	IsDelete  bool      `json:"Is delete"`
+	Contract  *Contract `json:"Contract,omitempty" metadata:"Contract,optional"` // Empty when the version is a delete.
+}
+
+// GetContractHistory returns every version of the contract, starting with the oldest one.
+func (s *SmartContract) GetContractHistory(ctx contractapi.TransactionContextInterface, ID string) ([]ContractVersion, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history of %s: %v", ID, err)
+	}
+	defer resultsIterator.Close()
+
+	versions := []ContractVersion{}
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		version := ContractVersion{
+			TxID:     modification.TxId,
+			IsDelete: modification.IsDelete,
+		}
+		if modification.Timestamp != nil {
+			version.Timestamp = time.Unix(modification.Timestamp.Seconds, int64(modification.Timestamp.Nanos)).UTC().Format(time.RFC3339)
+		}
+		if !modification.IsDelete {
+			var contract Contract
+			if err := json.Unmarshal(modification.Value, &contract); err != nil {
+				return nil, err
+			}
+			version.Contract = &contract
+		}
+		versions = append(versions, version)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("the contract %s does not exist", ID)
+	}
+
+	// Fabric returns the newest version first, but a timeline reads better from the oldest.
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+
+	// The caller is checked against the latest version of the contract that was not a delete.
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Contract != nil {
+			if err := checkAccess(ctx, "GetContractHistory", versions[i].Contract); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return versions, nil
+}
+
+// DisputeVersion: one version of a dispute as it was written by a transaction.
+type DisputeVersion struct {
+	TxID      string   `json:"Tx ID"`
+	Timestamp string   `json:"Timestamp"` // RFC 3339, in UTC.
+	IsDelete  bool     `json:"Is delete"`
+	Dispute   *Dispute `json:"Dispute,omitempty" metadata:"Dispute,optional"` // Empty when the version is a delete.
+}
+
+// GetDisputeHistory returns every version of the dispute, starting with the oldest one. It shows when the dispute was
+// escalated, ruled on, and enforced. Changes made while the dispute was still inside the contract are in GetContractHistory.
+func (s *SmartContract) GetDisputeHistory(ctx contractapi.TransactionContextInterface, ID string, DisputeID string) ([]DisputeVersion, error) {
+	if _, err := readContractFor(ctx, "GetDisputeHistory", ID); err != nil {
+		return nil, err
+	}
+
+	key, err := disputeKey(ctx, ID, DisputeID)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history of dispute %s: %v", DisputeID, err)
+	}
+	defer resultsIterator.Close()
+
+	versions := []DisputeVersion{}
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		version := DisputeVersion{
+			TxID:     modification.TxId,
+			IsDelete: modification.IsDelete,
+		}
+		if modification.Timestamp != nil {
+			version.Timestamp = time.Unix(modification.Timestamp.Seconds, int64(modification.Timestamp.Nanos)).UTC().Format(time.RFC3339)
+		}
+		if !modification.IsDelete {
+			var dispute Dispute
+			if err := json.Unmarshal(modification.Value, &dispute); err != nil {
+				return nil, err
+			}
+			version.Dispute = &dispute
+		}
+		versions = append(versions, version)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("There is no matching disputes with the given ID: %s ", DisputeID)
+	}
+
+	// Fabric returns the newest version first, but a timeline reads better from the oldest.
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+	return versions, nil
+}
@@ -0,0 +1,228 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Certificate attributes that every identity using this chaincode must be enrolled with.
+const (
+	roleAttribute  = "role"    // Can only be employer, employee, authority, arbitrator, or regulator.
+	partyAttribute = "partyID" // The Employer.ID or Employee.ID the identity acts for. Not needed for the other roles.
+	// An employee's partyID can be their cleartext ID or its pseudonym. getCaller always returns the pseudonym.
+)
+
+// The roles an identity can hold.
+const (
+	RoleEmployer   = "employer"
+	RoleEmployee   = "employee"
+	RoleAuthority  = "authority"  // A government officer of the country that owns the MSP.
+	RoleArbitrator = "arbitrator" // A member of a neutral organization that rules on escalated disputes. See arbitratorMSPs.
+	RoleRegulator  = "regulator"  // A labor regulator of the country that owns the MSP. Sets the country's rule pack, see compliance.go.
+)
+
+// The relation a caller must have with a contract to run a transaction on it.
+const (
+	asEmployer   = "employer"
+	asEmployee   = "employee"
+	asAuthority  = "authority"  // An authority of either the employer's or the employee's country.
+	asArbitrator = "arbitrator" // An arbitrator of one of the arbitratorMSPs. Not tied to a country.
+)
+
+// accessRules is the role matrix. Every transaction that touches a contract must be listed here.
+var accessRules = map[string][]string{
+	"HandleAddContract":      {asEmployer},
+	"ApproveContract":        {asEmployer, asEmployee, asAuthority}, // approvalParty decides if the caller is a required approver.
+	"WithdrawApproval":       {asEmployer, asEmployee, asAuthority},
+	"UpdateContract":         {asEmployer}, // Only while the contract is Pending. Later changes go through amendments.
+	"ProposeAmendment":       {asEmployer, asEmployee},
+	"AcceptAmendment":        {asEmployer, asEmployee}, // getAmendmentForDecision checks that the caller did not propose it.
+	"RejectAmendment":        {asEmployer, asEmployee},
+	"GetAmendments":          {asEmployer, asEmployee, asAuthority},
+	"ExtendContract":         {asEmployer},
+	"TerminateContract":      {asEmployer, asEmployee, asAuthority},
+	"RejectContract":         {asEmployer, asEmployee, asAuthority}, // approvalParty decides if the caller is a required approver.
+	"SuspendContract":        {asAuthority},
+	"ResumeContract":         {asAuthority},
+	"CompleteContract":       {asEmployer, asAuthority},
+	"IssueDispute":           {asEmployee},
+	"UpdateDispute":          {asEmployee},
+	"CloseDispute":           {asEmployee},
+	"RespondToDispute":       {asEmployer},
+	"EscalateDispute":        {asEmployer, asEmployee},
+	"RecordRuling":           {asArbitrator},
+	"EnforceRuling":          {asArbitrator, asAuthority},
+	"GetDisputes":            {asEmployer, asEmployee, asAuthority, asArbitrator},
+	"GetDisputeHistory":      {asEmployer, asEmployee, asAuthority, asArbitrator},
+	"RecordSalaryPayment":    {asEmployer},
+	"ConfirmPaymentReceived": {asEmployee},
+	"RejectPayment":          {asEmployee},
+	"GetPayments":            {asEmployer, asEmployee, asAuthority, asArbitrator}, // Arbitrators don't see the amounts.
+	"GetArrears":             {asEmployer, asEmployee, asAuthority},
+	"GetSalarySchedule":      {asEmployer, asEmployee, asAuthority},
+	"RequestLeave":           {asEmployee},
+	"ApproveLeave":           {asEmployer},
+	"DenyLeave":              {asEmployer},
+	"DisputeLeave":           {asEmployee},
+	"GetLeaveRequests":       {asEmployer, asEmployee, asAuthority, asArbitrator},
+	"GetLeaveBalance":        {asEmployer, asEmployee, asAuthority, asArbitrator},
+	"AttachDocument":         {asEmployer, asEmployee, asAuthority},
+	"VerifyDocument":         {asEmployer, asEmployee, asAuthority, asArbitrator},
+	"GetDocuments":           {asEmployer, asEmployee, asAuthority, asArbitrator},
+	"SignContract":           {asEmployer, asEmployee},
+	"GetContractTerms":       {asEmployer, asEmployee, asAuthority, asArbitrator},
+	"ReadContract":           {asEmployer, asEmployee, asAuthority, asArbitrator},
+	"GetContractHistory":     {asEmployer, asEmployee, asAuthority},
+	"ReadPrivateDetails":     {asEmployer, asEmployee, asAuthority}, // Checked by ReadContract before merging the private collection.
+}
+
+// countryMSPs maps the Country field of employers and employees to the MSP that represents that country in the network.
+var countryMSPs = map[string]string{
+	"Saudi Arabia": "CountryAMSP",
+	"India":        "CountryBMSP",
+}
+
+// arbitratorMSPs lists the neutral organizations whose arbitrators can rule on escalated disputes.
+var arbitratorMSPs = []string{"ArbitratorMSP"}
+
+// Caller: describes the identity that submitted the current transaction.
+type Caller struct {
+	MSPID   string
+	Role    string
+	PartyID string // The pseudonym of the ID for employees, see pseudonym.go.
+}
+
+// getCaller reads the MSP ID and certificate attributes of the client identity.
+// Will return an error if the identity has no valid role.
+func getCaller(ctx contractapi.TransactionContextInterface) (Caller, error) {
+	identity := ctx.GetClientIdentity()
+	mspID, err := identity.GetMSPID()
+	if err != nil {
+		return Caller{}, fmt.Errorf("failed to read the caller MSP ID: %v", err)
+	}
+	role, found, err := identity.GetAttributeValue(roleAttribute)
+	if err != nil {
+		return Caller{}, fmt.Errorf("failed to read the caller role: %v", err)
+	}
+	if !found {
+		return Caller{}, fmt.Errorf("the caller identity has no %s attribute", roleAttribute)
+	}
+	partyID, _, err := identity.GetAttributeValue(partyAttribute)
+	if err != nil {
+		return Caller{}, fmt.Errorf("failed to read the caller party ID: %v", err)
+	}
+
+	caller := Caller{MSPID: mspID, Role: role, PartyID: partyID}
+	switch role {
+	case RoleEmployer, RoleEmployee:
+		if partyID == "" {
+			return Caller{}, fmt.Errorf("the %s identity has no %s attribute", role, partyAttribute)
+		}
+		// Employees are known by the pseudonym of their ID on the ledger. A partyID attribute that already holds the pseudonym
+		// is used as it is, so the salt is only read from the private collection for a certificate that holds the cleartext ID.
+		if role == RoleEmployee && !isPseudonym(partyID) {
+			if caller.PartyID, err = employeePseudonym(ctx, partyID); err != nil {
+				return Caller{}, fmt.Errorf("the partyID attribute of the caller holds the cleartext employee ID, which can't be pseudonymized: %v", err)
+			}
+		}
+	case RoleAuthority, RoleRegulator:
+	case RoleArbitrator:
+		if !isArbitratorMSP(mspID) {
+			return Caller{}, fmt.Errorf("the %s role can only be held by identities of %v", role, arbitratorMSPs)
+		}
+	default:
+		return Caller{}, fmt.Errorf("unknown caller role: %s", role)
+	}
+	return caller, nil
+}
+
+// Will return the MSP that represents the given country. If the country is not mapped the name itself is used as an MSP ID.
+func countryMSP(country string) string {
+	if mspID, ok := countryMSPs[country]; ok {
+		return mspID
+	}
+	return country
+}
+
+// Will return true if the MSP is one of the arbitratorMSPs.
+func isArbitratorMSP(mspID string) bool {
+	for _, arbitratorMSP := range arbitratorMSPs {
+		if mspID == arbitratorMSP {
+			return true
+		}
+	}
+	return false
+}
+
+// Will return true if the caller has the given relation with the contract. The employer and the employee must also call
+// from the MSP of their country, so the partyID attribute alone, which any organization can put in a certificate, is not enough.
+func (c Caller) is(relation string, contract *Contract) bool {
+	switch relation {
+	case asEmployer:
+		return c.Role == RoleEmployer && c.PartyID == contract.Employer.ID && c.MSPID == countryMSP(contract.Employer.Country)
+	case asEmployee:
+		return c.Role == RoleEmployee && c.PartyID == contract.Employee.ID && c.MSPID == countryMSP(contract.Employee.Country)
+	case asAuthority:
+		return c.Role == RoleAuthority &&
+			(c.MSPID == countryMSP(contract.Employer.Country) || c.MSPID == countryMSP(contract.Employee.Country))
+	case asArbitrator:
+		return c.Role == RoleArbitrator
+	}
+	return false
+}
+
+// Will return true if the caller matches one of the relations accessRules lists for the transaction.
+func (c Caller) can(transaction string, contract *Contract) bool {
+	for _, relation := range accessRules[transaction] {
+		if c.is(relation, contract) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccess will return an error unless the caller matches one of the relations accessRules lists for the transaction.
+func checkAccess(ctx contractapi.TransactionContextInterface, transaction string, contract *Contract) error {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if caller.can(transaction, contract) {
+		return nil
+	}
+	return fmt.Errorf("access denied: caller with role %s from %s can not call %s on contract %s", caller.Role, caller.MSPID, transaction, contract.ID)
+}
+
+// readContractFor reads the contract from the world state and checks the caller against accessRules for the transaction.
+func readContractFor(ctx contractapi.TransactionContextInterface, transaction string, ID string) (*Contract, error) {
+	contractJSON, err := ctx.GetStub().GetState(ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if contractJSON == nil {
+		return nil, fmt.Errorf("the contract %s does not exist", ID)
+	}
+	var contract Contract
+	if err := json.Unmarshal(contractJSON, &contract); err != nil {
+		return nil, err
+	}
+	if err := checkAccess(ctx, transaction, &contract); err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// checkPartyAccess is used by transactions that are not tied to one contract.
+// Will return nil if the caller is an authority or acts for the given party.
+func checkPartyAccess(ctx contractapi.TransactionContextInterface, transaction string, role string, partyID string) error {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if caller.Role == RoleAuthority || (caller.Role == role && caller.PartyID == partyID) {
+		return nil
+	}
+	return fmt.Errorf("access denied: caller with role %s from %s can not call %s for %s", caller.Role, caller.MSPID, transaction, partyID)
+}
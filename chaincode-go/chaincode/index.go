@@ -0,0 +1,119 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Composite key object types used to find the contracts of a party without scanning the whole world state.
+const (
+	employerIndex = "employer~contract" // employer ID, contract ID
+	employeeIndex = "employee~contract" // employee ID, contract ID
+)
+
+// Composite keys only need a key, but Fabric doesn't store empty values.
+var indexValue = []byte{0x00}
+
+// indexContract writes the employer and employee composite keys of the contract.
+func indexContract(ctx contractapi.TransactionContextInterface, contract *Contract) error {
+	if err := putIndexKey(ctx, employerIndex, contract.Employer.ID, contract.ID); err != nil {
+		return err
+	}
+	return putIndexKey(ctx, employeeIndex, contract.Employee.ID, contract.ID)
+}
+
+// reindexParties removes the composite keys of the old employer and employee if the update changed them, and writes the new ones.
+func reindexParties(ctx contractapi.TransactionContextInterface, oldContract *Contract, newContract *Contract) error {
+	if oldContract.Employer.ID != newContract.Employer.ID {
+		if err := deleteIndexKey(ctx, employerIndex, oldContract.Employer.ID, oldContract.ID); err != nil {
+			return err
+		}
+	}
+	if oldContract.Employee.ID != newContract.Employee.ID {
+		if err := deleteIndexKey(ctx, employeeIndex, oldContract.Employee.ID, oldContract.ID); err != nil {
+			return err
+		}
+	}
+	return indexContract(ctx, newContract)
+}
+
+func putIndexKey(ctx contractapi.TransactionContextInterface, objectType string, partyID string, contractID string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, []string{partyID, contractID})
+	if err != nil {
+		return fmt.Errorf("failed to create the %s key: %v", objectType, err)
+	}
+	return ctx.GetStub().PutState(key, indexValue)
+}
+
+func deleteIndexKey(ctx contractapi.TransactionContextInterface, objectType string, partyID string, contractID string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, []string{partyID, contractID})
+	if err != nil {
+		return fmt.Errorf("failed to create the %s key: %v", objectType, err)
+	}
+	return ctx.GetStub().DelState(key)
+}
+
+// getIndexedContracts returns every contract listed under the given party in the given index.
+func getIndexedContracts(ctx contractapi.TransactionContextInterface, objectType string, partyID string) ([]Contract, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, []string{partyID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var contracts []Contract
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		contractJSON, err := ctx.GetStub().GetState(keyParts[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if contractJSON == nil {
+			continue // The index is ahead of a contract that no longer exists.
+		}
+		var contract Contract
+		if err := json.Unmarshal(contractJSON, &contract); err != nil {
+			return nil, err
+		}
+		if err := loadDisputes(ctx, &contract); err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, contract)
+	}
+	return contracts, nil
+}
+
+/*
+* This method will write the employer and employee composite keys of every contract in the world state, and return how many contracts it indexed.
+* It is only needed once, for contracts created before the indexes existed. Only authorities can run it.
+ */
+func (s *SmartContract) ReindexContracts(ctx contractapi.TransactionContextInterface) (int, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if caller.Role != RoleAuthority {
+		return 0, fmt.Errorf("access denied: caller with role %s from %s can not call ReindexContracts", caller.Role, caller.MSPID)
+	}
+
+	contracts, err := s.getAllContracts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, contract := range contracts {
+		if err := indexContract(ctx, contract); err != nil {
+			return 0, err
+		}
+	}
+	return len(contracts), nil
+}
@@ -0,0 +1,480 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Leave requests are stored as their own records under this composite key object type.
+const leaveKeyType = "contract~leave" // contract ID, leave request ID
+
+// AnnualLeave: the paid leave a contract grants. It accrues every completed month of service.
+type AnnualLeave struct {
+	Days        int    `json:"Days"`                                                  // Calendar days per year of service.
+	Description string `json:"Description,omitempty" metadata:"Description,optional"` // Free text, kept from contracts written before leave was typed.
+}
+
+// UnmarshalJSON also accepts the free text contracts had before leave was typed, such as "30 days".
+// The number the text starts with is taken as the days per year.
+func (a *AnnualLeave) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		digits := strings.TrimSpace(text)
+		if end := strings.IndexFunc(digits, func(r rune) bool { return !unicode.IsDigit(r) }); end >= 0 {
+			digits = digits[:end]
+		}
+		days, _ := strconv.Atoi(digits)
+		*a = AnnualLeave{Days: days, Description: text}
+		return nil
+	}
+	type plainLeave AnnualLeave // Has no UnmarshalJSON, so it doesn't loop.
+	var leave plainLeave
+	if err := json.Unmarshal(data, &leave); err != nil {
+		return err
+	}
+	*a = AnnualLeave(leave)
+	return nil
+}
+
+// validate will return an error if no leave can accrue from the contract terms.
+func (a AnnualLeave) validate() error {
+	if a.Days <= 0 || a.Days > 365 {
+		return fmt.Errorf("the annual leave must be between 1 and 365 days a year")
+	}
+	return nil
+}
+
+// LeaveRequest: a period of annual leave the employee asked for. Its status moves from Pending to Approved or Denied.
+// A Denied request, or a Pending one whose start date has arrived, can be disputed with DisputeLeave.
+type LeaveRequest struct {
+	ID            string `json:"ID"`
+	ContractID    string `json:"Contract ID"`
+	Status        string `json:"Status"` // Can only be Pending, Approved, or Denied.
+	From          string `json:"From"`
+	To            string `json:"To"`
+	Days          int    `json:"Days"` // Calendar days from From to To, both included.
+	Reason        string `json:"Reason"`
+	RequestedDate string `json:"Requested date"`
+	DecisionDate  string `json:"Decision date,omitempty" metadata:"Decision date,optional"`
+	DenialReason  string `json:"Denial reason,omitempty" metadata:"Denial reason,optional"`
+	DisputeID     string `json:"Dispute ID,omitempty" metadata:"Dispute ID,optional"` // Set by DisputeLeave.
+}
+
+// LeaveBalance: the annual leave of a contract on a given date.
+type LeaveBalance struct {
+	ContractID  string `json:"Contract ID"`
+	Date        string `json:"Date"`
+	DaysPerYear int    `json:"Days per year"`
+	Accrued     int    `json:"Accrued"`   // Every completed month of service adds a twelfth of DaysPerYear, rounded down.
+	Taken       int    `json:"Taken"`     // The days of every Approved request.
+	Requested   int    `json:"Requested"` // The days of every Pending request.
+	Available   int    `json:"Available"` // Accrued minus Taken. Negative while approved leave is ahead of what has accrued.
+}
+
+func leaveKey(ctx contractapi.TransactionContextInterface, contractID string, leaveID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(leaveKeyType, []string{contractID, leaveID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", leaveKeyType, err)
+	}
+	return key, nil
+}
+
+func putLeaveRequest(ctx contractapi.TransactionContextInterface, request *LeaveRequest) error {
+	key, err := leaveKey(ctx, request.ContractID, request.ID)
+	if err != nil {
+		return err
+	}
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, requestJSON)
+}
+
+// getLeaveRequest returns the leave request with the given ID. Will return an error if the contract has no such request.
+func getLeaveRequest(ctx contractapi.TransactionContextInterface, contractID string, leaveID string) (*LeaveRequest, error) {
+	key, err := leaveKey(ctx, contractID, leaveID)
+	if err != nil {
+		return nil, err
+	}
+	requestJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if requestJSON == nil {
+		return nil, fmt.Errorf("There is no matching leave requests with the given ID: %s ", leaveID)
+	}
+	var request LeaveRequest
+	if err := json.Unmarshal(requestJSON, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// listLeaveRequests returns every leave request of the contract ordered by ID.
+func listLeaveRequests(ctx contractapi.TransactionContextInterface, contractID string) ([]LeaveRequest, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(leaveKeyType, []string{contractID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	requests := []LeaveRequest{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var request LeaveRequest
+		if err := json.Unmarshal(queryResponse.Value, &request); err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	sort.SliceStable(requests, func(i, j int) bool {
+		return lessID(requests[i].ID, requests[j].ID)
+	})
+	return requests, nil
+}
+
+// Will return the leave days the contract has accrued on the given date. Service stops counting when the contract ends.
+func accruedLeave(contract *Contract, date time.Time) (int, error) {
+	startDate, err := time.Parse(dateFormat, contract.StartDate)
+	if err != nil {
+		return 0, fmt.Errorf("the start date of the contract is not valid: %v", err)
+	}
+	endDate, err := time.Parse(dateFormat, contract.EndDate)
+	if err != nil {
+		return 0, fmt.Errorf("the end date of the contract is not valid: %v", err)
+	}
+	if endedDate, err := time.Parse(dateFormat, contract.EndedDate); err == nil && endedDate.Before(endDate) {
+		endDate = endedDate
+	}
+	if date.After(endDate) {
+		date = endDate
+	}
+
+	months := (date.Year()-startDate.Year())*12 + int(date.Month()-startDate.Month())
+	if date.Day() < startDate.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0, nil
+	}
+	return contract.Benefits.AnnualLeave.Days * months / 12, nil
+}
+
+// Will return the days of the Approved and the Pending requests, leaving out the request with the given ID.
+func leaveInUse(requests []LeaveRequest, except string) (int, int) {
+	taken, requested := 0, 0
+	for _, request := range requests {
+		if request.ID == except {
+			continue
+		}
+		switch request.Status {
+		case "Approved":
+			taken += request.Days
+		case "Pending":
+			requested += request.Days
+		}
+	}
+	return taken, requested
+}
+
+/*
+* This method will ask the employer for annual leave from From to To, both included, in MM/DD/YYYY format.
+* The leave can't start in the past, end after the contract, or overlap another Pending or Approved request.
+* The days asked for must have accrued by the start of the leave, after the days already taken or requested.
+ */
+func (s *SmartContract) RequestLeave(ctx contractapi.TransactionContextInterface, ID string, From string, To string, Reason string) (bool, error) {
+	contract, err := readContractFor(ctx, "RequestLeave", ID)
+	if err != nil {
+		return false, err
+	}
+	if contract.Status != StatusActive {
+		return false, fmt.Errorf("Leave can only be requested on an Active contract. The contract is %s", contract.Status)
+	}
+
+	fromDate, err := time.Parse(dateFormat, From)
+	if err != nil {
+		return false, fmt.Errorf("the start of the leave must be in MM/DD/YYYY format: %v", err)
+	}
+	toDate, err := time.Parse(dateFormat, To)
+	if err != nil {
+		return false, fmt.Errorf("the end of the leave must be in MM/DD/YYYY format: %v", err)
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	endDate, err := time.Parse(dateFormat, contract.EndDate)
+	if err != nil {
+		return false, fmt.Errorf("the end date of the contract is not valid: %v", err)
+	}
+	if toDate.Before(fromDate) {
+		return false, fmt.Errorf("the leave can't end before it starts")
+	}
+	if fromDate.Before(currentDate.Truncate(24 * time.Hour)) {
+		return false, fmt.Errorf("the leave can't start in the past")
+	}
+	if toDate.After(endDate) {
+		return false, fmt.Errorf("the leave can't end after the contract, which ends on %s", contract.EndDate)
+	}
+
+	requests, err := listLeaveRequests(ctx, contract.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, request := range requests {
+		if request.Status != "Pending" && request.Status != "Approved" {
+			continue
+		}
+		otherFrom, _ := time.Parse(dateFormat, request.From)
+		otherTo, _ := time.Parse(dateFormat, request.To)
+		if !fromDate.After(otherTo) && !toDate.Before(otherFrom) {
+			return false, fmt.Errorf("The leave overlaps the %s request %s, from %s to %s.", request.Status, request.ID, request.From, request.To)
+		}
+	}
+
+	days := int(toDate.Sub(fromDate).Hours()/24) + 1
+	accrued, err := accruedLeave(contract, fromDate)
+	if err != nil {
+		return false, err
+	}
+	taken, requested := leaveInUse(requests, "")
+	if days > accrued-taken-requested {
+		return false, fmt.Errorf("the leave is %d days, but only %d days are available on %s", days, accrued-taken-requested, From)
+	}
+
+	request := LeaveRequest{
+		ID:            strconv.Itoa(len(requests)),
+		ContractID:    contract.ID,
+		Status:        "Pending",
+		From:          From,
+		To:            To,
+		Days:          days,
+		Reason:        Reason,
+		RequestedDate: currentDate.Format(dateFormat),
+	}
+	if err := putLeaveRequest(ctx, &request); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventLeaveRequested, contract, request.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// getLeaveForDecision reads the contract and a Pending leave request, and checks the caller against accessRules for the transaction.
+func getLeaveForDecision(ctx contractapi.TransactionContextInterface, transaction string, ID string, LeaveID string) (*Contract, *LeaveRequest, error) {
+	contract, err := readContractFor(ctx, transaction, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isFinal(contract.Status) {
+		return nil, nil, fmt.Errorf("The contract is %s, so its leave requests can't be decided.", contract.Status)
+	}
+	request, err := getLeaveRequest(ctx, contract.ID, LeaveID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if request.Status != "Pending" {
+		return nil, nil, fmt.Errorf("Only Pending leave requests can be decided. The request is %s", request.Status)
+	}
+	return contract, request, nil
+}
+
+/*
+* This method will approve a Pending leave request. The days must still be available on the start of the leave.
+ */
+func (s *SmartContract) ApproveLeave(ctx contractapi.TransactionContextInterface, ID string, LeaveID string) (bool, error) {
+	contract, request, err := getLeaveForDecision(ctx, "ApproveLeave", ID, LeaveID)
+	if err != nil {
+		return false, err
+	}
+
+	fromDate, err := time.Parse(dateFormat, request.From)
+	if err != nil {
+		return false, err
+	}
+	accrued, err := accruedLeave(contract, fromDate)
+	if err != nil {
+		return false, err
+	}
+	requests, err := listLeaveRequests(ctx, contract.ID)
+	if err != nil {
+		return false, err
+	}
+	taken, _ := leaveInUse(requests, request.ID)
+	if request.Days > accrued-taken {
+		return false, fmt.Errorf("the leave is %d days, but only %d days are available on %s", request.Days, accrued-taken, request.From)
+	}
+
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	request.Status = "Approved"
+	request.DecisionDate = currentDate.Format(dateFormat)
+
+	if err := putLeaveRequest(ctx, request); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventLeaveApproved, contract, request.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will deny a Pending leave request.
+* @Param Reason must not be empty. It stays on the request, so the employee can use it in a dispute.
+ */
+func (s *SmartContract) DenyLeave(ctx contractapi.TransactionContextInterface, ID string, LeaveID string, Reason string) (bool, error) {
+	if strings.TrimSpace(Reason) == "" {
+		return false, fmt.Errorf("the reason for denying leave can not be empty")
+	}
+	contract, request, err := getLeaveForDecision(ctx, "DenyLeave", ID, LeaveID)
+	if err != nil {
+		return false, err
+	}
+
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	request.Status = "Denied"
+	request.DecisionDate = currentDate.Format(dateFormat)
+	request.DenialReason = Reason
+
+	if err := putLeaveRequest(ctx, request); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventLeaveDenied, contract, request.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will return the leave requests of the contract.
+* @Param status can be Pending, Approved, or Denied to filter the requests, or empty to return all of them.
+ */
+func (s *SmartContract) GetLeaveRequests(ctx contractapi.TransactionContextInterface, ID string, status string) ([]LeaveRequest, error) {
+	if status != "" && status != "Pending" && status != "Approved" && status != "Denied" {
+		return nil, fmt.Errorf("unknown leave request status: %s. It can only be Pending, Approved, Denied, or empty", status)
+	}
+	contract, err := readContractFor(ctx, "GetLeaveRequests", ID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := listLeaveRequests(ctx, contract.ID)
+	if err != nil {
+		return nil, err
+	}
+	matching := []LeaveRequest{}
+	for _, request := range requests {
+		if status == "" || request.Status == status {
+			matching = append(matching, request)
+		}
+	}
+	return matching, nil
+}
+
+/*
+* This method will return the annual leave of the contract today.
+* Leave that isn't taken carries over, so the balance covers the whole contract.
+ */
+func (s *SmartContract) GetLeaveBalance(ctx contractapi.TransactionContextInterface, ID string) (*LeaveBalance, error) {
+	contract, err := readContractFor(ctx, "GetLeaveBalance", ID)
+	if err != nil {
+		return nil, err
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accrued, err := accruedLeave(contract, currentDate)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := listLeaveRequests(ctx, contract.ID)
+	if err != nil {
+		return nil, err
+	}
+	taken, requested := leaveInUse(requests, "")
+
+	return &LeaveBalance{
+		ContractID:  contract.ID,
+		Date:        currentDate.Format(dateFormat),
+		DaysPerYear: contract.Benefits.AnnualLeave.Days,
+		Accrued:     accrued,
+		Taken:       taken,
+		Requested:   requested,
+		Available:   accrued - taken,
+	}, nil
+}
+
+/*
+* This method will issue a leave dispute about a leave request, and link the request to it as evidence.
+* The request must be Denied, or still Pending on its start date. A request can only be disputed once.
+* The other parameters are the same as IssueDispute. It takes no revision, since the dispute is about the request rather than the terms.
+ */
+func (s *SmartContract) DisputeLeave(ctx contractapi.TransactionContextInterface, ID string, LeaveID string, Severity string, Content string) (bool, error) {
+	severity, err := parseSeverity(Severity)
+	if err != nil {
+		return false, err
+	}
+	curDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	dispute := Dispute{
+		ID:              "1", // Set by fileDispute.
+		Status:          "Active",
+		Category:        CategoryLeave,
+		Severity:        severity,
+		ResponseDueDate: responseDueDate(CategoryLeave, curDate),
+		LastUpdatedDate: curDate.Format(dateFormat),
+		Content:         Content,
+		Responses:       []Response{},
+		LeaveRequestID:  LeaveID,
+	}
+
+	var request *LeaveRequest
+	check := func(contract *Contract) error {
+		request, err = getLeaveRequest(ctx, contract.ID, LeaveID)
+		if err != nil {
+			return err
+		}
+		if request.DisputeID != "" {
+			return fmt.Errorf("The leave request %s is already disputed in dispute %s.", request.ID, request.DisputeID)
+		}
+		fromDate, err := time.Parse(dateFormat, request.From)
+		if err != nil {
+			return err
+		}
+		unanswered := request.Status == "Pending" && !curDate.Before(fromDate)
+		if request.Status != "Denied" && !unanswered {
+			return fmt.Errorf("Only Denied leave requests, or Pending ones whose start date has arrived, can be disputed. The request is %s", request.Status)
+		}
+		return nil
+	}
+	if err := s.fileDispute(ctx, "DisputeLeave", ID, &dispute, check); err != nil {
+		return false, err
+	}
+
+	request.DisputeID = dispute.ID
+	if err := putLeaveRequest(ctx, request); err != nil {
+		return false, err
+	}
+	return true, nil
+}
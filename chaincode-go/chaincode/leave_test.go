@@ -0,0 +1,111 @@
+package chaincode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccruedLeave(t *testing.T) {
+	contract := func(endedDate string) *Contract {
+		return &Contract{StartDate: "01/02/2026", EndDate: "05/05/2028", EndedDate: endedDate,
+			Benefits: Benefits{AnnualLeave: AnnualLeave{Days: 30}}}
+	}
+
+	tests := []struct {
+		name     string
+		contract *Contract
+		date     string
+		want     int
+		wantErr  string
+	}{
+		{"before the start", contract(""), "12/01/2025", 0, ""},
+		{"on the start", contract(""), "01/02/2026", 0, ""},
+		{"a day short of a month", contract(""), "02/01/2026", 0, ""},
+		{"one month", contract(""), "02/02/2026", 2, ""},
+		{"six months", contract(""), "07/02/2026", 15, ""},
+		{"one year", contract(""), "01/02/2027", 30, ""},
+		{"on the end date", contract(""), "05/05/2028", 70, ""},
+		{"after the end date", contract(""), "01/01/2030", 70, ""},
+		{"ended early", contract("03/01/2027"), "01/01/2030", 32, ""},
+		{"broken start date", &Contract{StartDate: "2026-01-02", EndDate: "05/05/2028"}, "01/01/2027", 0, "the start date of the contract is not valid"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			date, err := time.Parse(dateFormat, test.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := accruedLeave(test.contract, date)
+			wantError(t, err, test.wantErr)
+			if got != test.want {
+				t.Fatalf("want %d days, got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRequestLeave(t *testing.T) {
+	tests := []struct {
+		name    string
+		caller  testIdentity
+		from    string
+		to      string
+		wantErr string
+	}{
+		{"every accrued day", testEmployee, "04/02/2026", "04/08/2026", ""},
+		{"a day more than accrued", testEmployee, "04/02/2026", "04/09/2026", "the leave is 8 days, but only 7 days are available on 04/02/2026"},
+		{"later, once more has accrued", testEmployee, "07/02/2026", "07/16/2026", ""},
+		{"starts in the past", testEmployee, "03/31/2026", "04/01/2026", "the leave can't start in the past"},
+		{"ends before it starts", testEmployee, "04/08/2026", "04/02/2026", "the leave can't end before it starts"},
+		{"ends after the contract", testEmployee, "05/01/2028", "05/06/2028", "the leave can't end after the contract"},
+		{"not MM/DD/YYYY", testEmployee, "2026-04-02", "04/08/2026", "the start of the leave must be in MM/DD/YYYY format"},
+		{"employer", testEmployer, "04/02/2026", "04/03/2026", "access denied"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLedger(t, "01/01/2026")
+			l.activeContract("11930", "01/02/2026", "05/05/2028")
+			l.setDate("04/01/2026")
+
+			_, err := l.contract.RequestLeave(l.as(test.caller), "11930", test.from, test.to, "Family visit")
+			wantError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestLeaveBalance(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+	l.activeContract("11930", "01/02/2026", "05/05/2028")
+	l.setDate("04/01/2026")
+	l.ok(l.contract.RequestLeave(l.as(testEmployee), "11930", "04/02/2026", "04/04/2026", "Family visit"))
+	l.ok(l.contract.RequestLeave(l.as(testEmployee), "11930", "04/06/2026", "04/07/2026", "Moving"))
+	l.ok(l.contract.RequestLeave(l.as(testEmployee), "11930", "04/08/2026", "04/08/2026", "Doctor"))
+
+	_, err := l.contract.RequestLeave(l.as(testEmployee), "11930", "04/07/2026", "04/08/2026", "Overlaps")
+	wantError(t, err, "The leave overlaps the Pending request 1")
+
+	l.ok(l.contract.ApproveLeave(l.as(testEmployer), "11930", "0"))
+	l.ok(l.contract.DenyLeave(l.as(testEmployer), "11930", "1", "Busy season"))
+
+	tests := []struct {
+		today string
+		want  LeaveBalance
+	}{
+		{"04/01/2026", LeaveBalance{Date: "04/01/2026", Accrued: 5, Taken: 3, Requested: 1, Available: 2}},
+		{"01/02/2027", LeaveBalance{Date: "01/02/2027", Accrued: 30, Taken: 3, Requested: 1, Available: 27}},
+		{"01/01/2030", LeaveBalance{Date: "01/01/2030", Accrued: 70, Taken: 3, Requested: 1, Available: 67}},
+	}
+	for _, test := range tests {
+		t.Run(test.today, func(t *testing.T) {
+			l.setDate(test.today)
+			balance, err := l.contract.GetLeaveBalance(l.as(testEmployee), "11930")
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.want.ContractID, test.want.DaysPerYear = "11930", 30
+			if *balance != test.want {
+				t.Fatalf("want %+v, got %+v", test.want, *balance)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package chaincode
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// testIdentity: a client identity with the role and party ID attributes getCaller reads.
+type testIdentity struct {
+	mspID   string
+	role    string
+	partyID string
+}
+
+func (i testIdentity) GetID() (string, error)    { return i.role + ":" + i.partyID, nil }
+func (i testIdentity) GetMSPID() (string, error) { return i.mspID, nil }
+
+func (i testIdentity) GetAttributeValue(name string) (string, bool, error) {
+	switch name {
+	case roleAttribute:
+		return i.role, i.role != "", nil
+	case partyAttribute:
+		return i.partyID, i.partyID != "", nil
+	}
+	return "", false, nil
+}
+
+func (i testIdentity) AssertAttributeValue(name string, value string) error { return nil }
+func (i testIdentity) GetX509Certificate() (*x509.Certificate, error)       { return nil, nil }
+
+// The identities of the test ledger. The employer is in Saudi Arabia and the employee in India, see countryMSPs.
+var (
+	testEmployer    = testIdentity{"CountryAMSP", RoleEmployer, "Comp-1"}
+	testEmployee    = testIdentity{"CountryBMSP", RoleEmployee, "44110"}
+	testAuthorityA  = testIdentity{"CountryAMSP", RoleAuthority, ""}
+	testAuthorityB  = testIdentity{"CountryBMSP", RoleAuthority, ""}
+	testRegulatorA  = testIdentity{"CountryAMSP", RoleRegulator, ""}
+	testOtherWorker = testIdentity{"CountryBMSP", RoleEmployee, "99"}
+)
+
+// testContractJSON is the contract HandleAddContract reads in the tests. Its private details come from testPrivateDetails.
+const testContractJSON = `{'ID': '11930','Notes': 'N/A','Start date': '01/02/2026','End date': '05/05/2028','Extension details': 'N/A',
+'Employer': {'ID': 'Comp-1','Name': 'Company A','Employer address and contact details': '','Country': 'Saudi Arabia'},
+'Employee': {'ID': '','Name': '','Employee address and contact details': '','Country': 'India'},
+'Job': {'Position': 'Developer','Level': 'Senior','Description': 'Builds the payroll system'},
+'Benefits': {'Currency': 'SAR','Salary': 0,'Annual increase': '3%','Annual leave': '30 days','Housing': 0,'Allowances': 0,'Other benefits': 'Tickets'}}`
+
+// testPrivateDetails are the salary and contact details of testContractJSON, as passed in the transient map.
+const testPrivateDetails = `{"Salary":{"Minor units":1000000,"Currency":"SAR"},"Housing":{"Minor units":200000,"Currency":"SAR"},
+"Allowances":{"Minor units":150000,"Currency":"SAR"},"Employer address and contact details":"First st",
+"Employee address and contact details":"Second st","Salt":"test-salt","Employee ID":"44110"}`
+
+// testStub is a MockStub that skips composite keys in open ended range queries, like a peer does, and serves a transient map.
+type testStub struct {
+	*shimtest.MockStub
+	transient         map[string][]byte
+	outsideCollection bool // Private data reads fail, like on a peer that is not a member of the collection.
+	rangeQueries      int  // The range queries run so far. A peer checks them again at commit, so concurrent writes make them fail.
+}
+
+func (s *testStub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	s.rangeQueries++
+	if startKey == "" {
+		startKey = "\x01"
+	}
+	if endKey == "" {
+		endKey = "\U0010FFFF"
+	}
+	return s.MockStub.GetStateByRange(startKey, endKey)
+}
+
+func (s *testStub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	s.rangeQueries++
+	return s.MockStub.GetStateByPartialCompositeKey(objectType, keys)
+}
+
+// GetQueryResultWithPagination runs the selector of a CouchDB query over the world state in key order, like CouchDB without a sort.
+// The bookmark is the key to start from. Only the operators the chaincode sends are supported, see matchesSelector.
+func (s *testStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	var parsed struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	if err := json.Unmarshal([]byte(query), &parsed); err != nil {
+		return nil, nil, err
+	}
+	page := &testIterator{}
+	metadata := &pb.QueryResponseMetadata{}
+	for key := s.Keys.Front(); key != nil; key = key.Next() {
+		name := key.Value.(string)
+		if name < bookmark {
+			continue
+		}
+		var document map[string]interface{}
+		if json.Unmarshal(s.State[name], &document) != nil || !matchesSelector(document, parsed.Selector) {
+			continue
+		}
+		if len(page.results) == int(pageSize) {
+			metadata.Bookmark = name
+			break
+		}
+		page.results = append(page.results, &queryresult.KV{Key: name, Value: s.State[name]})
+	}
+	metadata.FetchedRecordsCount = int32(len(page.results))
+	return page, metadata, nil
+}
+
+func (s *testStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	page, _, err := s.GetQueryResultWithPagination(query, int32(len(s.State)+1), "")
+	return page, err
+}
+
+// matchesSelector supports plain values, the $eq, $ne, $lt, $lte, $gt, $gte, $in, and $exists operators, and dotted field names.
+func matchesSelector(document map[string]interface{}, selector map[string]interface{}) bool {
+	for field, condition := range selector {
+		var value interface{} = document
+		for _, name := range strings.Split(field, ".") {
+			fields, _ := value.(map[string]interface{})
+			value = fields[name]
+		}
+		operators, ok := condition.(map[string]interface{})
+		if !ok {
+			operators = map[string]interface{}{"$eq": condition}
+		}
+		for operator, operand := range operators {
+			var matches bool
+			switch operator {
+			case "$eq":
+				matches = value != nil && compareJSON(value, operand) == 0
+			case "$ne":
+				matches = value == nil || compareJSON(value, operand) != 0
+			case "$lt":
+				matches = value != nil && compareJSON(value, operand) < 0
+			case "$lte":
+				matches = value != nil && compareJSON(value, operand) <= 0
+			case "$gt":
+				matches = value != nil && compareJSON(value, operand) > 0
+			case "$gte":
+				matches = value != nil && compareJSON(value, operand) >= 0
+			case "$in":
+				values, _ := operand.([]interface{})
+				for _, candidate := range values {
+					matches = matches || (value != nil && compareJSON(value, candidate) == 0)
+				}
+			case "$exists":
+				matches = (value != nil) == operand
+			default:
+				panic("matchesSelector does not support " + operator)
+			}
+			if !matches {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// compareJSON compares two JSON values, as numbers when both are numbers and as text otherwise.
+func compareJSON(a interface{}, b interface{}) int {
+	numberA, okA := a.(float64)
+	numberB, okB := b.(float64)
+	if okA && okB {
+		if numberA < numberB {
+			return -1
+		}
+		if numberA > numberB {
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// testIterator serves the results of a query run by testStub.
+type testIterator struct {
+	results []*queryresult.KV
+}
+
+func (i *testIterator) HasNext() bool { return len(i.results) > 0 }
+func (i *testIterator) Close() error  { return nil }
+
+func (i *testIterator) Next() (*queryresult.KV, error) {
+	result := i.results[0]
+	i.results = i.results[1:]
+	return result, nil
+}
+
+func (s *testStub) GetTransient() (map[string][]byte, error) {
+	return s.transient, nil
+}
+
+func (s *testStub) GetPrivateData(collection string, key string) ([]byte, error) {
+	if s.outsideCollection {
+		return nil, fmt.Errorf("private data matching public hash version is not available")
+	}
+	return s.MockStub.GetPrivateData(collection, key)
+}
+
+// testLedger runs transactions against a mock ledger, with the contract clock fixed to a date set by the test.
+// The employer and the employee of testContractJSON are registered and Verified.
+type testLedger struct {
+	t        *testing.T
+	stub     *testStub
+	contract *SmartContract
+	keys     map[testIdentity]ed25519.PrivateKey
+	txs      int
+	event    *pb.ChaincodeEvent // The event of the current transaction, once read from the mock.
+}
+
+// newTestLedger returns a ledger whose clock is fixed on the given date, in MM/DD/YYYY format.
+func newTestLedger(t *testing.T, date string) *testLedger {
+	t.Helper()
+	l := &testLedger{
+		t:        t,
+		stub:     &testStub{MockStub: shimtest.NewMockStub("contracts", nil), transient: map[string][]byte{}},
+		contract: &SmartContract{},
+		keys:     map[testIdentity]ed25519.PrivateKey{},
+	}
+	l.setDate(date)
+	l.stub.transient[saltTransientKey] = []byte("0123456789abcdef")
+	l.stub.transient[identityTransientKey] = []byte(`{"ID":"44110","Name":"John Doe"}`)
+	l.stub.transient[privateTransientKey] = []byte(testPrivateDetails)
+
+	l.ok(l.contract.SetPseudonymSalt(l.as(testAuthorityA)))
+	l.ok(l.contract.RegisterEmployer(l.as(testAuthorityA), "Comp-1", "Company A", "Saudi Arabia"))
+	l.ok(l.contract.SetPartyStatus(l.as(testAuthorityA), RoleEmployer, "Comp-1", string(PartyVerified), 1))
+	l.ok(l.contract.RegisterEmployee(l.as(testEmployee), "India"))
+	l.ok(l.contract.SetPartyStatus(l.as(testAuthorityB), RoleEmployee, "44110", string(PartyVerified), 1))
+	return l
+}
+
+// setDate fixes the contract clock on the given date, in MM/DD/YYYY format.
+func (l *testLedger) setDate(date string) {
+	l.t.Helper()
+	now, err := time.Parse(dateFormat, date)
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	l.contract.Clock = FixedClock(now)
+}
+
+// as starts a new transaction submitted by the given identity.
+func (l *testLedger) as(identity testIdentity) contractapi.TransactionContextInterface {
+	l.txs++
+	l.drainEvents() // The mock blocks once its event channel is full.
+	l.event = nil
+	l.stub.MockTransactionStart(fmt.Sprintf("tx%d", l.txs))
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(l.stub)
+	ctx.SetClientIdentity(identity)
+	return ctx
+}
+
+// ok fails the test if the transaction failed.
+func (l *testLedger) ok(ok bool, err error) {
+	l.t.Helper()
+	if err != nil || !ok {
+		l.t.Fatalf("the transaction failed: %v", err)
+	}
+}
+
+// addContract creates the contract of testContractJSON with the given ID and dates.
+func (l *testLedger) addContract(ID string, startDate string, endDate string) {
+	l.t.Helper()
+	contractJSON := strings.NewReplacer("'ID': '11930'", "'ID': '"+ID+"'",
+		"'Start date': '01/02/2026'", "'Start date': '"+startDate+"'",
+		"'End date': '05/05/2028'", "'End date': '"+endDate+"'").Replace(testContractJSON)
+	l.ok(l.contract.HandleAddContract(l.as(testEmployer), contractJSON))
+}
+
+// sign signs the current terms of the contract as the given party, registering a key for it the first time.
+func (l *testLedger) sign(identity testIdentity, ID string) {
+	l.t.Helper()
+	key, ok := l.keys[identity]
+	if !ok {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			l.t.Fatal(err)
+		}
+		publicDER, err := x509.MarshalPKIXPublicKey(public)
+		if err != nil {
+			l.t.Fatal(err)
+		}
+		l.ok(l.contract.RegisterSigningKey(l.as(identity), base64.StdEncoding.EncodeToString(publicDER)))
+		key, l.keys[identity] = private, private
+	}
+	terms, err := l.contract.GetContractTerms(l.as(identity), ID)
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	digest, err := hex.DecodeString(terms.SHA256)
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	revision := l.read(ID).Revision
+	l.ok(l.contract.SignContract(l.as(identity), ID, base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest)), revision))
+}
+
+// activeContract creates the contract, has both parties sign it, and both authorities approve it.
+func (l *testLedger) activeContract(ID string, startDate string, endDate string) {
+	l.t.Helper()
+	l.addContract(ID, startDate, endDate)
+	l.sign(testEmployer, ID)
+	l.sign(testEmployee, ID)
+	l.approve(testAuthorityA, ID)
+	l.approve(testAuthorityB, ID)
+}
+
+// approve approves the current revision of the contract as the given authority.
+func (l *testLedger) approve(identity testIdentity, ID string) {
+	l.t.Helper()
+	revision := l.read(ID).Revision
+	l.ok(l.contract.ApproveContract(l.as(identity), ID, revision))
+}
+
+// read returns the contract as the employer sees it.
+func (l *testLedger) read(ID string) *Contract {
+	l.t.Helper()
+	contract, err := l.contract.ReadContract(l.as(testEmployer), ID)
+	if err != nil {
+		l.t.Fatal(err)
+	}
+	return contract
+}
+
+func (l *testLedger) drainEvents() {
+	for len(l.stub.ChaincodeEventsChannel) > 0 {
+		l.event = <-l.stub.ChaincodeEventsChannel
+	}
+}
+
+// lastEvent returns the name and the payload of the event of the last transaction, or empty strings if it set none.
+func (l *testLedger) lastEvent() (string, string) {
+	l.drainEvents()
+	if l.event == nil {
+		return "", ""
+	}
+	return l.event.EventName, string(l.event.Payload)
+}
+
+// wantError fails the test unless err holds the given text, or is nil when the text is empty.
+func wantError(t *testing.T, err error, text string) {
+	t.Helper()
+	if text == "" && err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "" && (err == nil || !strings.Contains(err.Error(), text)) {
+		t.Fatalf("want an error with %q, got %v", text, err)
+	}
+}
@@ -0,0 +1,227 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money: an amount in the minor units of an ISO 4217 currency, so 1050 minor units of SAR are 10.50 SAR.
+// Amounts were bare whole numbers before Money, see UnmarshalJSON and migrate for how they are still read from the world state.
+// Transaction arguments must give the currency of every amount, see checkArgument.
+type Money struct {
+	MinorUnits int64  `json:"Minor units"`
+	Currency   string `json:"Currency"` // The ISO 4217 code, such as SAR or INR. Empty only for amounts not migrated yet.
+}
+
+// currencyExponents: the number of decimals of every ISO 4217 currency that has more or fewer than 2.
+// Every code in currencyCodes not listed here has 2.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// currencyCodes: the active ISO 4217 currency codes. Funds and precious metals are left out, since salaries are not paid in them.
+var currencyCodes = strings.Fields(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL BSD BTN BWP BYN BZD
+	CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD
+	GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT
+	LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR
+	NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP
+	STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XOF
+	XPF YER ZAR ZMW ZWL`)
+
+// currencyExponent returns the number of decimals of the currency. Will return false if the code is not an ISO 4217 currency.
+func currencyExponent(currency string) (int, bool) {
+	for _, code := range currencyCodes {
+		if code == currency {
+			if exponent, ok := currencyExponents[code]; ok {
+				return exponent, true
+			}
+			return 2, true
+		}
+	}
+	return 0, false
+}
+
+// checkCurrency will return an error if the code is not an ISO 4217 currency. Codes are upper case, as in the standard.
+func checkCurrency(currency string) error {
+	if _, ok := currencyExponent(currency); !ok {
+		return fmt.Errorf("unknown currency: %s. It must be an ISO 4217 code such as SAR or INR", currency)
+	}
+	return nil
+}
+
+// UnmarshalJSON also accepts a bare whole number, the way amounts were stored before Money.
+// It is kept in MinorUnits as major units with an empty Currency, until migrate is called with the currency of the contract.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var major int64
+	if err := json.Unmarshal(data, &major); err == nil {
+		*m = Money{MinorUnits: major}
+		return nil
+	}
+	type plainMoney Money // Has no UnmarshalJSON, so it doesn't loop.
+	var money plainMoney
+	if err := json.Unmarshal(data, &money); err != nil {
+		return err
+	}
+	*m = Money(money)
+	return nil
+}
+
+// migrate converts an amount without a currency, read from an older record, to the minor units of the given currency.
+// Amounts that already have a currency are left as they are, and so is every amount when the currency is empty.
+// Unknown currencies from older records are taken to have 2 decimals.
+// Only call it on records read from the world state or the private collection. A new amount without a currency
+// can't be told apart from an older one, so it would be multiplied by the minor units of the currency, see checkArgument.
+func (m *Money) migrate(currency string) {
+	if m.Currency != "" || currency == "" {
+		return
+	}
+	exponent, ok := currencyExponent(currency)
+	if !ok {
+		exponent = 2
+	}
+	for i := 0; i < exponent; i++ {
+		m.MinorUnits *= 10
+	}
+	m.Currency = currency
+}
+
+// checkArgument will return an error if an amount passed to a transaction has no currency.
+// Only 0, the same in every currency, can leave it out.
+// @Param name is the field the amount is for, as shown in the error.
+func (m Money) checkArgument(name string) error {
+	if m.Currency == "" && m.MinorUnits != 0 {
+		return fmt.Errorf("the %s has no currency. Give it in minor units with its currency, such as {\"Minor units\": 1050, \"Currency\": \"SAR\"}", name)
+	}
+	return nil
+}
+
+// validate will return an error if the amount is negative or not in the given currency.
+// @Param name is the field the amount is for, as shown in the error.
+func (m Money) validate(name string, currency string) error {
+	if m.Currency != currency {
+		return fmt.Errorf("the %s is in %s, but the contract is paid in %s", name, m.Currency, currency)
+	}
+	if m.MinorUnits < 0 {
+		return fmt.Errorf("the %s can not be negative", name)
+	}
+	return nil
+}
+
+// Will return the sum of both amounts, in the currency of the first one.
+func (m Money) plus(other Money) Money {
+	return Money{MinorUnits: m.MinorUnits + other.MinorUnits, Currency: m.Currency}
+}
+
+// Will return the amount in major units followed by the currency, for example "10000.50 SAR".
+func (m Money) String() string {
+	exponent, ok := currencyExponent(m.Currency)
+	if !ok {
+		exponent = 2
+	}
+	sign := ""
+	units := m.MinorUnits
+	if units < 0 {
+		sign, units = "-", -units
+	}
+	text := strconv.FormatInt(units, 10)
+	if exponent > 0 {
+		for len(text) <= exponent {
+			text = "0" + text
+		}
+		text = text[:len(text)-exponent] + "." + text[len(text)-exponent:]
+	}
+	return strings.TrimSpace(sign + text + " " + m.Currency)
+}
+
+// checkArgumentBenefits will return an error if an amount of the benefits in the contract JSON passed to a transaction
+// has no currency. It reads the JSON again without Benefits.UnmarshalJSON, which would migrate the amount, see Money.checkArgument.
+func checkArgumentBenefits(contractJSON []byte) error {
+	type plainBenefits Benefits // Has no UnmarshalJSON, so the amounts are not migrated.
+	var argument struct {
+		Benefits plainBenefits `json:"Benefits"`
+	}
+	if err := json.Unmarshal(contractJSON, &argument); err != nil {
+		return err
+	}
+	benefits := argument.Benefits
+	amounts := []struct {
+		name   string
+		amount *Money
+	}{
+		{"Salary", &benefits.Salary},
+		{"Housing", &benefits.Housing},
+		{"Allowances", &benefits.Allowances},
+		{"annual increase Amount", benefits.AnnualIncrease.Amount},
+		{"annual increase Cap", benefits.AnnualIncrease.Cap},
+	}
+	for _, amount := range amounts {
+		if amount.amount == nil {
+			continue
+		}
+		if err := amount.amount.checkArgument(amount.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON migrates the amounts of older contracts, which were bare numbers, to the currency of the contract.
+// Contract JSON passed to a transaction is checked by checkArgumentBenefits first, so only the amounts of older records are migrated.
+func (b *Benefits) UnmarshalJSON(data []byte) error {
+	type plainBenefits Benefits // Has no UnmarshalJSON, so it doesn't loop.
+	var benefits plainBenefits
+	if err := json.Unmarshal(data, &benefits); err != nil {
+		return err
+	}
+	*b = Benefits(benefits)
+	b.migrate()
+	return nil
+}
+
+// Will migrate every amount of the benefits that has no currency to the currency of the contract.
+func (b *Benefits) migrate() {
+	b.Salary.migrate(b.Currency)
+	b.Housing.migrate(b.Currency)
+	b.Allowances.migrate(b.Currency)
+	if b.AnnualIncrease.Amount != nil {
+		b.AnnualIncrease.Amount.migrate(b.Currency)
+	}
+	if b.AnnualIncrease.Cap != nil {
+		b.AnnualIncrease.Cap.migrate(b.Currency)
+	}
+}
+
+// validate will return an error if the currency is not ISO 4217, an amount is in another currency or negative,
+// or the annual increase or leave can't be applied.
+func (b Benefits) validate() error {
+	if err := checkCurrency(b.Currency); err != nil {
+		return err
+	}
+	amounts := []struct {
+		name  string
+		money *Money
+	}{
+		{"salary", &b.Salary},
+		{"housing", &b.Housing},
+		{"allowances", &b.Allowances},
+		{"annual increase amount", b.AnnualIncrease.Amount},
+		{"annual increase cap", b.AnnualIncrease.Cap},
+	}
+	for _, amount := range amounts {
+		if amount.money == nil {
+			continue
+		}
+		if err := amount.money.validate(amount.name, b.Currency); err != nil {
+			return err
+		}
+	}
+	if err := b.AnnualIncrease.validate(b.Salary); err != nil {
+		return err
+	}
+	return b.AnnualLeave.validate()
+}
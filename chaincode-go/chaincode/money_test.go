@@ -0,0 +1,166 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckCurrency(t *testing.T) {
+	tests := []struct {
+		currency     string
+		wantExponent int
+		wantErr      string
+	}{
+		{"SAR", 2, ""},
+		{"INR", 2, ""},
+		{"JPY", 0, ""},
+		{"KWD", 3, ""},
+		{"sar", 0, "unknown currency: sar"},
+		{"XAU", 0, "unknown currency: XAU"},
+		{"", 0, "unknown currency: "},
+	}
+	for _, test := range tests {
+		t.Run(test.currency, func(t *testing.T) {
+			wantError(t, checkCurrency(test.currency), test.wantErr)
+			exponent, ok := currencyExponent(test.currency)
+			if ok != (test.wantErr == "") || exponent != test.wantExponent {
+				t.Fatalf("want %d decimals, got %d and %v", test.wantExponent, exponent, ok)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{MinorUnits: 1000050, Currency: "SAR"}, "10000.50 SAR"},
+		{Money{MinorUnits: 5, Currency: "SAR"}, "0.05 SAR"},
+		{Money{MinorUnits: 0, Currency: "SAR"}, "0.00 SAR"},
+		{Money{MinorUnits: -150, Currency: "SAR"}, "-1.50 SAR"},
+		{Money{MinorUnits: 1500, Currency: "JPY"}, "1500 JPY"},
+		{Money{MinorUnits: 1250, Currency: "KWD"}, "1.250 KWD"},
+		{Money{MinorUnits: 1250}, "12.50"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.money.String(); got != test.want {
+				t.Fatalf("want %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyMigrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		currency string
+		want     Money
+	}{
+		{"bare number in SAR", `10000`, "SAR", Money{MinorUnits: 1000000, Currency: "SAR"}},
+		{"bare number in JPY", `10000`, "JPY", Money{MinorUnits: 10000, Currency: "JPY"}},
+		{"bare number in KWD", `10000`, "KWD", Money{MinorUnits: 10000000, Currency: "KWD"}},
+		{"bare number in an unknown currency", `10000`, "SR", Money{MinorUnits: 1000000, Currency: "SR"}},
+		{"bare number without a currency", `10000`, "", Money{MinorUnits: 10000}},
+		{"already Money", `{"Minor units":1050,"Currency":"SAR"}`, "INR", Money{MinorUnits: 1050, Currency: "SAR"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var money Money
+			if err := json.Unmarshal([]byte(test.json), &money); err != nil {
+				t.Fatal(err)
+			}
+			money.migrate(test.currency)
+			if money != test.want {
+				t.Fatalf("want %+v, got %+v", test.want, money)
+			}
+		})
+	}
+}
+
+func TestBenefitsMigrate(t *testing.T) {
+	var benefits Benefits
+	older := `{"Currency":"SAR","Salary":10000,"Housing":2000,"Allowances":1500,"Annual increase":"500","Annual leave":"30 days"}`
+	if err := json.Unmarshal([]byte(older), &benefits); err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []struct {
+		money Money
+		want  int64
+	}{
+		{benefits.Salary, 1000000},
+		{benefits.Housing, 200000},
+		{benefits.Allowances, 150000},
+		{*benefits.AnnualIncrease.Amount, 50000},
+	} {
+		if got.money != (Money{MinorUnits: got.want, Currency: "SAR"}) {
+			t.Fatalf("want %d minor units of SAR, got %+v", got.want, got.money)
+		}
+	}
+	if err := benefits.validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAmountArguments(t *testing.T) {
+	newIncrease := `'Annual increase': {'Type': 'fixed','Amount': {'Minor units': 50000,'Currency': 'SAR'}}`
+	tests := []struct {
+		name     string
+		contract string
+		private  string
+		wantErr  string
+	}{
+		{"with currencies", strings.Replace(testContractJSON, "'Annual increase': '3%'", newIncrease, 1), testPrivateDetails, ""},
+		{"salary without a currency", testContractJSON, strings.Replace(testPrivateDetails, `{"Minor units":1000000,"Currency":"SAR"}`, "1000000", 1),
+			"the Salary has no currency"},
+		{"increase without a currency", strings.Replace(testContractJSON, "'Annual increase': '3%'", "'Annual increase': '500'", 1), testPrivateDetails,
+			"the annual increase Amount has no currency"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLedger(t, "01/01/2026")
+			l.stub.transient[privateTransientKey] = []byte(test.private)
+			_, err := l.contract.HandleAddContract(l.as(testEmployer), test.contract)
+			wantError(t, err, test.wantErr)
+			if test.wantErr == "" {
+				if salary := l.read("11930").Benefits.Salary; salary != (Money{MinorUnits: 1000000, Currency: "SAR"}) {
+					t.Fatalf("want the salary kept in minor units, got %+v", salary)
+				}
+			}
+		})
+	}
+}
+
+func TestBenefitsValidate(t *testing.T) {
+	sar := func(minorUnits int64) Money { return Money{MinorUnits: minorUnits, Currency: "SAR"} }
+	benefits := func(change func(b *Benefits)) Benefits {
+		b := Benefits{Currency: "SAR", Salary: sar(1000000), Housing: sar(200000), Allowances: sar(150000),
+			AnnualIncrease: AnnualIncrease{Type: IncreasePercent, Percent: 3}, AnnualLeave: AnnualLeave{Days: 30}}
+		change(&b)
+		return b
+	}
+
+	tests := []struct {
+		name     string
+		benefits Benefits
+		wantErr  string
+	}{
+		{"valid", benefits(func(b *Benefits) {}), ""},
+		{"unknown currency", benefits(func(b *Benefits) { b.Currency = "Riyal" }), "unknown currency: Riyal"},
+		{"salary in another currency", benefits(func(b *Benefits) { b.Salary.Currency = "USD" }), "the salary is in USD, but the contract is paid in SAR"},
+		{"negative housing", benefits(func(b *Benefits) { b.Housing = sar(-1) }), "the housing can not be negative"},
+		{"cap in another currency", benefits(func(b *Benefits) { b.AnnualIncrease.Cap = &Money{MinorUnits: 2000000, Currency: "USD"} }),
+			"the annual increase cap is in USD, but the contract is paid in SAR"},
+		{"cap below the salary", benefits(func(b *Benefits) { b.AnnualIncrease.Cap = &Money{MinorUnits: 10, Currency: "SAR"} }),
+			"the cap of the annual increase can't be lower than the salary"},
+		{"no leave", benefits(func(b *Benefits) { b.AnnualLeave.Days = 0 }), "the annual leave must be between 1 and 365 days a year"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wantError(t, test.benefits.validate(), test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,526 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Salary payments are stored as their own records under this composite key object type.
+// Their amounts are kept in privateCollection under the same key, like the salary they are compared with.
+const paymentKeyType = "contract~payment" // contract ID, payment ID
+
+// The transient map key RecordSalaryPayment reads the amount from.
+const paymentTransientKey = "payment_private"
+
+// The format of the month a salary payment is for.
+const periodFormat = "01/2006"
+
+// Payment: a salary payment the employer recorded for one month of the contract. The employee confirms it once the money arrives,
+// or rejects it if it never did or the amount is wrong.
+type Payment struct {
+	ID                 string `json:"ID"`
+	ContractID         string `json:"Contract ID"`
+	Period             string `json:"Period"` // The month the payment is for, in MM/YYYY format.
+	Amount             Money  `json:"Amount"` // Zero in the world state, see PaymentPrivateDetails.
+	Currency           string `json:"Currency"`
+	Reference          string `json:"Reference"` // The bank or transfer reference, so the payment can be traced outside the ledger.
+	RecordedDate       string `json:"Recorded date"`
+	Confirmed          bool   `json:"Confirmed"`
+	ConfirmedDate      string `json:"Confirmed date,omitempty" metadata:"Confirmed date,optional"`
+	Rejected           bool   `json:"Rejected"`
+	RejectedDate       string `json:"Rejected date,omitempty" metadata:"Rejected date,optional"`
+	RejectionReason    string `json:"Rejection reason,omitempty" metadata:"Rejection reason,optional"`
+	PrivateDetailsHash string `json:"Private details hash"`
+}
+
+// PaymentPrivateDetails: the amount of a payment. It is kept in privateCollection and only its hash is stored with the payment.
+type PaymentPrivateDetails struct {
+	Amount Money  `json:"Amount"` // Older payments hold a bare number, migrated to the payment currency when read.
+	Salt   string `json:"Salt"`   // A random value from the client, so the hash can't be guessed from common amounts.
+}
+
+// PeriodArrears: what was due and paid for one month of a contract.
+type PeriodArrears struct {
+	Period    string `json:"Period"`
+	Expected  Money  `json:"Expected"`
+	Paid      Money  `json:"Paid"`      // Every payment the employer recorded for the month.
+	Confirmed Money  `json:"Confirmed"` // The part of Paid the employee confirmed receiving.
+	Disputed  Money  `json:"Disputed"`  // The part of Paid the employee has neither confirmed nor rejected yet.
+	Rejected  Money  `json:"Rejected"`  // The part of Paid the employee rejected.
+	Arrears   Money  `json:"Arrears"`   // Expected minus Confirmed. Overpaying a month doesn't cover another one.
+}
+
+// ArrearsReport: the salary due on a contract so far, compared with the payments recorded for it.
+type ArrearsReport struct {
+	ContractID     string          `json:"Contract ID"`
+	Currency       string          `json:"Currency"`
+	Periods        []PeriodArrears `json:"Periods"`
+	TotalExpected  Money           `json:"Total expected"`
+	TotalPaid      Money           `json:"Total paid"`
+	TotalConfirmed Money           `json:"Total confirmed"`
+	TotalDisputed  Money           `json:"Total disputed"`
+	TotalRejected  Money           `json:"Total rejected"`
+	TotalArrears   Money           `json:"Total arrears"`
+}
+
+func paymentKey(ctx contractapi.TransactionContextInterface, contractID string, paymentID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(paymentKeyType, []string{contractID, paymentID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", paymentKeyType, err)
+	}
+	return key, nil
+}
+
+func putPayment(ctx contractapi.TransactionContextInterface, payment *Payment) error {
+	key, err := paymentKey(ctx, payment.ContractID, payment.ID)
+	if err != nil {
+		return err
+	}
+	payment.Amount = Money{Currency: payment.Currency} // The amount only lives in the private collection.
+	paymentJSON, err := json.Marshal(payment)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, paymentJSON)
+}
+
+// listPayments returns every payment of the contract ordered by ID. The amounts are left empty.
+func listPayments(ctx contractapi.TransactionContextInterface, contractID string) ([]Payment, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(paymentKeyType, []string{contractID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	payments := []Payment{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var payment Payment
+		if err := json.Unmarshal(queryResponse.Value, &payment); err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	sort.SliceStable(payments, func(i, j int) bool {
+		return lessID(payments[i].ID, payments[j].ID)
+	})
+	return payments, nil
+}
+
+// loadPaymentAmounts reads the amounts of the payments from the private collection.
+func loadPaymentAmounts(ctx contractapi.TransactionContextInterface, payments []Payment) error {
+	for i := range payments {
+		key, err := paymentKey(ctx, payments[i].ContractID, payments[i].ID)
+		if err != nil {
+			return err
+		}
+		privateJSON, err := ctx.GetStub().GetPrivateData(privateCollection, key)
+		if err != nil {
+			return fmt.Errorf("failed to read the private details: %v", err)
+		}
+		if privateJSON == nil {
+			return fmt.Errorf("the private details of payment %s are missing", payments[i].ID)
+		}
+		var private PaymentPrivateDetails
+		if err := json.Unmarshal(privateJSON, &private); err != nil {
+			return err
+		}
+		private.Amount.migrate(payments[i].Currency)
+		payments[i].Amount = private.Amount
+	}
+	return nil
+}
+
+// readPaymentDetails reads the amount of a payment from the transient map. It is never passed as an argument,
+// because arguments are written to the block.
+func readPaymentDetails(ctx contractapi.TransactionContextInterface) (*PaymentPrivateDetails, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the transient map: %v", err)
+	}
+	privateJSON, ok := transientMap[paymentTransientKey]
+	if !ok {
+		return nil, fmt.Errorf("the payment amount must be passed in the transient map under %s", paymentTransientKey)
+	}
+
+	var private PaymentPrivateDetails
+	if err := json.Unmarshal(privateJSON, &private); err != nil {
+		return nil, fmt.Errorf("Error Unmarshaling payment details: %s", err)
+	}
+	if private.Amount.MinorUnits <= 0 {
+		return nil, fmt.Errorf("the payment amount must be more than 0")
+	}
+	if err := private.Amount.checkArgument("payment amount"); err != nil {
+		return nil, err
+	}
+	if private.Salt == "" {
+		return nil, fmt.Errorf("the payment details must have a Salt")
+	}
+	return &private, nil
+}
+
+// Will return the hex SHA-256 of the payment details. It is stored with the payment so both parties can prove the amount.
+func (p *PaymentPrivateDetails) hash() (string, error) {
+	privateJSON, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(privateJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Will return the first day of every month of the contract that is over on the given date, up to the end of the contract.
+func duePeriods(contract *Contract, now time.Time) ([]time.Time, error) {
+	startDate, err := time.Parse(dateFormat, contract.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("the start date of the contract is not valid: %v", err)
+	}
+	lastDate, err := time.Parse(dateFormat, contract.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("the end date of the contract is not valid: %v", err)
+	}
+	if endedDate, err := time.Parse(dateFormat, contract.EndedDate); err == nil && endedDate.Before(lastDate) {
+		lastDate = endedDate
+	}
+
+	periods := []time.Time{}
+	month := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !month.After(lastDate) && !now.Before(month.AddDate(0, 1, 0)) {
+		periods = append(periods, month)
+		month = month.AddDate(0, 1, 0)
+	}
+	return periods, nil
+}
+
+// checkSalaryDue will return an error if the contract never started, so it has no salary due.
+func checkSalaryDue(contract *Contract) error {
+	if contract.Status == StatusPending || contract.Status == StatusRejected {
+		return fmt.Errorf("A %s contract has no salary due.", contract.Status)
+	}
+	return nil
+}
+
+/*
+* This method will record a salary payment the employer made for one month of the contract.
+* @Param Period is the month the payment is for, in MM/YYYY format. It must be within the contract and can't be in the future.
+* @Param Currency must be the currency of the contract, and Reference the bank or transfer reference of the payment.
+* The amount comes from the payment_private transient key, so it is not written to the block. See PaymentPrivateDetails.
+ */
+func (s *SmartContract) RecordSalaryPayment(ctx contractapi.TransactionContextInterface, ID string, Period string, Currency string, Reference string) (bool, error) {
+	contract, err := readContractFor(ctx, "RecordSalaryPayment", ID)
+	if err != nil {
+		return false, err
+	}
+	if err := checkSalaryDue(contract); err != nil {
+		return false, err
+	}
+	private, err := readPaymentDetails(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	period, err := time.Parse(periodFormat, Period)
+	if err != nil {
+		return false, fmt.Errorf("the period must be in MM/YYYY format: %v", err)
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	startDate, err := time.Parse(dateFormat, contract.StartDate)
+	if err != nil {
+		return false, fmt.Errorf("the start date of the contract is not valid: %v", err)
+	}
+	endDate, err := time.Parse(dateFormat, contract.EndDate)
+	if err != nil {
+		return false, fmt.Errorf("the end date of the contract is not valid: %v", err)
+	}
+	if !period.AddDate(0, 1, 0).After(startDate) || period.After(endDate) {
+		return false, fmt.Errorf("the period %s is outside the contract, which runs from %s to %s", Period, contract.StartDate, contract.EndDate)
+	}
+	if period.After(currentDate) {
+		return false, fmt.Errorf("the period %s is in the future", Period)
+	}
+	if !strings.EqualFold(strings.TrimSpace(Currency), contract.Benefits.Currency) {
+		return false, fmt.Errorf("the contract salary is paid in %s, not %s", contract.Benefits.Currency, Currency)
+	}
+	if err := private.Amount.validate("payment amount", contract.Benefits.Currency); err != nil {
+		return false, err
+	}
+	Reference = strings.TrimSpace(Reference)
+	if Reference == "" {
+		return false, fmt.Errorf("the payment reference can not be empty")
+	}
+
+	payments, err := listPayments(ctx, contract.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, payment := range payments {
+		if payment.Reference == Reference {
+			return false, fmt.Errorf("The payment %s already has the reference %s.", payment.ID, Reference)
+		}
+	}
+
+	payment := Payment{
+		ID:           strconv.Itoa(len(payments)),
+		ContractID:   contract.ID,
+		Period:       period.Format(periodFormat),
+		Currency:     contract.Benefits.Currency,
+		Reference:    Reference,
+		RecordedDate: currentDate.Format(dateFormat),
+	}
+
+	key, err := paymentKey(ctx, payment.ContractID, payment.ID)
+	if err != nil {
+		return false, err
+	}
+	payment.PrivateDetailsHash, err = private.hash()
+	if err != nil {
+		return false, err
+	}
+	privateJSON, err := json.Marshal(private)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollection, key, privateJSON); err != nil {
+		return false, fmt.Errorf("failed to write the private details: %v", err)
+	}
+
+	if err := putPayment(ctx, &payment); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventPaymentRecorded, contract, payment.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// readOpenPayment returns the payment of the contract. Will return an error unless the employee has yet to confirm or reject it.
+func readOpenPayment(ctx contractapi.TransactionContextInterface, contractID string, paymentID string) (*Payment, error) {
+	key, err := paymentKey(ctx, contractID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	paymentJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if paymentJSON == nil {
+		return nil, fmt.Errorf("There is no matching payments with the given ID: %s ", paymentID)
+	}
+	var payment Payment
+	if err := json.Unmarshal(paymentJSON, &payment); err != nil {
+		return nil, err
+	}
+	if payment.Confirmed {
+		return nil, fmt.Errorf("The payment %s was already confirmed on %s.", payment.ID, payment.ConfirmedDate)
+	}
+	if payment.Rejected {
+		return nil, fmt.Errorf("The payment %s was already rejected on %s.", payment.ID, payment.RejectedDate)
+	}
+	return &payment, nil
+}
+
+/*
+* This method will mark a salary payment as received. Only the employee of the contract can confirm it, and only once.
+ */
+func (s *SmartContract) ConfirmPaymentReceived(ctx contractapi.TransactionContextInterface, ID string, PaymentID string) (bool, error) {
+	contract, err := readContractFor(ctx, "ConfirmPaymentReceived", ID)
+	if err != nil {
+		return false, err
+	}
+	if err := checkSalaryDue(contract); err != nil {
+		return false, err
+	}
+	payment, err := readOpenPayment(ctx, contract.ID, PaymentID)
+	if err != nil {
+		return false, err
+	}
+
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	payment.Confirmed = true
+	payment.ConfirmedDate = currentDate.Format(dateFormat)
+
+	if err := putPayment(ctx, payment); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventPaymentConfirmed, contract, payment.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will mark a salary payment as never received, or received with the wrong amount. The month stays in arrears
+* until the employer records a payment the employee confirms. Only the employee of the contract can reject it, and only
+* a payment they have not confirmed.
+* @Param Reason explains what is wrong with the payment.
+ */
+func (s *SmartContract) RejectPayment(ctx contractapi.TransactionContextInterface, ID string, PaymentID string, Reason string) (bool, error) {
+	contract, err := readContractFor(ctx, "RejectPayment", ID)
+	if err != nil {
+		return false, err
+	}
+	if err := checkSalaryDue(contract); err != nil {
+		return false, err
+	}
+	payment, err := readOpenPayment(ctx, contract.ID, PaymentID)
+	if err != nil {
+		return false, err
+	}
+	Reason = strings.TrimSpace(Reason)
+	if Reason == "" {
+		return false, fmt.Errorf("the reason for rejecting the payment can not be empty")
+	}
+
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	payment.Rejected = true
+	payment.RejectedDate = currentDate.Format(dateFormat)
+	payment.RejectionReason = Reason
+
+	if err := putPayment(ctx, payment); err != nil {
+		return false, err
+	}
+	if err := s.emitRecordEvent(ctx, EventPaymentRejected, contract, payment.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will return the salary payments recorded for the contract.
+* The amounts are only filled in for callers who can read the private details.
+ */
+func (s *SmartContract) GetPayments(ctx contractapi.TransactionContextInterface, ID string) ([]Payment, error) {
+	contract, err := readContractFor(ctx, "GetPayments", ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSalaryDue(contract); err != nil {
+		return nil, err
+	}
+	payments, err := listPayments(ctx, contract.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if caller.can("ReadPrivateDetails", contract) {
+		if err := loadPaymentAmounts(ctx, payments); err != nil {
+			return nil, err
+		}
+	}
+	return payments, nil
+}
+
+/*
+* This method will compare the salary due on the contract so far with the payments recorded for it.
+* Every month of the contract is due once it is over, until the contract ends. A month is due in full, whatever day the contract started.
+* The salary of each month is the one in force on its last day, so amendments and annual increases are taken into account.
+* Only payments the employee confirmed count against the arrears. The ones they have not confirmed yet are reported as disputed.
+ */
+func (s *SmartContract) GetArrears(ctx contractapi.TransactionContextInterface, ID string) (*ArrearsReport, error) {
+	contract, err := readContractFor(ctx, "GetArrears", ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSalaryDue(contract); err != nil {
+		return nil, err
+	}
+	if err := loadPrivateDetails(ctx, contract); err != nil {
+		return nil, err
+	}
+	projection, err := newSalaryProjection(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := listPayments(ctx, contract.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadPaymentAmounts(ctx, payments); err != nil {
+		return nil, err
+	}
+
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+	periods, err := duePeriods(contract, currentDate)
+	if err != nil {
+		return nil, err
+	}
+
+	zero := Money{Currency: contract.Benefits.Currency}
+	report := ArrearsReport{
+		ContractID:     contract.ID,
+		Currency:       contract.Benefits.Currency,
+		Periods:        []PeriodArrears{},
+		TotalExpected:  zero,
+		TotalPaid:      zero,
+		TotalConfirmed: zero,
+		TotalDisputed:  zero,
+		TotalRejected:  zero,
+		TotalArrears:   zero,
+	}
+	for _, month := range periods {
+		period := PeriodArrears{
+			Period:    month.Format(periodFormat),
+			Expected:  projection.on(month.AddDate(0, 1, -1)),
+			Paid:      zero,
+			Confirmed: zero,
+			Disputed:  zero,
+			Rejected:  zero,
+			Arrears:   zero,
+		}
+		for _, payment := range payments {
+			if payment.Period != period.Period {
+				continue
+			}
+			period.Paid = period.Paid.plus(payment.Amount)
+			switch {
+			case payment.Confirmed:
+				period.Confirmed = period.Confirmed.plus(payment.Amount)
+			case payment.Rejected:
+				period.Rejected = period.Rejected.plus(payment.Amount)
+			default:
+				period.Disputed = period.Disputed.plus(payment.Amount)
+			}
+		}
+		if period.Confirmed.MinorUnits < period.Expected.MinorUnits {
+			period.Arrears.MinorUnits = period.Expected.MinorUnits - period.Confirmed.MinorUnits
+		}
+
+		report.Periods = append(report.Periods, period)
+		report.TotalExpected = report.TotalExpected.plus(period.Expected)
+		report.TotalPaid = report.TotalPaid.plus(period.Paid)
+		report.TotalConfirmed = report.TotalConfirmed.plus(period.Confirmed)
+		report.TotalDisputed = report.TotalDisputed.plus(period.Disputed)
+		report.TotalRejected = report.TotalRejected.plus(period.Rejected)
+		report.TotalArrears = report.TotalArrears.plus(period.Arrears)
+	}
+	return &report, nil
+}
@@ -0,0 +1,139 @@
+package chaincode
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// sarJSON returns the JSON of the given amount in major units of SAR.
+func sarJSON(major int64) string {
+	return `{"Minor units":` + strconv.FormatInt(major*100, 10) + `,"Currency":"SAR"}`
+}
+
+// recordPayment records a payment of the given amount in major units of SAR as the employer.
+func (l *testLedger) recordPayment(period string, amount int64, reference string) error {
+	l.stub.transient[paymentTransientKey] = []byte(`{"Amount":` + sarJSON(amount) + `,"Salt":"` + reference + `"}`)
+	_, err := l.contract.RecordSalaryPayment(l.as(testEmployer), "11930", period, "SAR", reference)
+	return err
+}
+
+func TestRecordSalaryPayment(t *testing.T) {
+	tests := []struct {
+		name      string
+		caller    testIdentity
+		period    string
+		currency  string
+		reference string
+		amount    string // The JSON of the amount.
+		wantErr   string
+	}{
+		{"last month", testEmployer, "03/2026", "SAR", "TRX-2", sarJSON(10000), ""},
+		{"the current month", testEmployer, "04/2026", "sar", "TRX-2", sarJSON(10000), ""},
+		{"next month", testEmployer, "05/2026", "SAR", "TRX-2", sarJSON(10000), "the period 05/2026 is in the future"},
+		{"before the contract", testEmployer, "12/2025", "SAR", "TRX-2", sarJSON(10000), "the period 12/2025 is outside the contract"},
+		{"another currency", testEmployer, "03/2026", "USD", "TRX-2", sarJSON(10000), "the contract salary is paid in SAR, not USD"},
+		{"no reference", testEmployer, "03/2026", "SAR", " ", sarJSON(10000), "the payment reference can not be empty"},
+		{"reference used before", testEmployer, "03/2026", "SAR", "TRX-1", sarJSON(10000), "already has the reference TRX-1"},
+		{"no amount", testEmployer, "03/2026", "SAR", "TRX-2", sarJSON(0), "the payment amount must be more than 0"},
+		{"negative amount", testEmployer, "03/2026", "SAR", "TRX-2", sarJSON(-1), "the payment amount must be more than 0"},
+		{"no currency", testEmployer, "03/2026", "SAR", "TRX-2", "1000000", "the payment amount has no currency"},
+		{"not MM/YYYY", testEmployer, "2026-03", "SAR", "TRX-2", sarJSON(10000), "the period must be in MM/YYYY format"},
+		{"employee", testEmployee, "03/2026", "SAR", "TRX-2", sarJSON(10000), "access denied"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLedger(t, "01/01/2026")
+			l.activeContract("11930", "01/02/2026", "05/05/2028")
+			l.setDate("04/15/2026")
+			if err := l.recordPayment("02/2026", 10000, "TRX-1"); err != nil {
+				t.Fatal(err)
+			}
+
+			l.stub.transient[paymentTransientKey] = []byte(`{"Amount":` + test.amount + `,"Salt":"s"}`)
+			_, err := l.contract.RecordSalaryPayment(l.as(test.caller), "11930", test.period, test.currency, test.reference)
+			wantError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestGetArrears(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+	l.activeContract("11930", "01/02/2026", "05/05/2028")
+	l.setDate("04/15/2026")
+	for _, payment := range []struct {
+		period    string
+		amount    int64
+		reference string
+	}{
+		{"01/2026", 10000, "TRX-0"}, // Confirmed.
+		{"02/2026", 10000, "TRX-1"}, // Rejected.
+		{"03/2026", 6000, "TRX-2"},  // Neither yet.
+		{"03/2026", 4000, "TRX-3"},  // Confirmed.
+	} {
+		if err := l.recordPayment(payment.period, payment.amount, payment.reference); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.ok(l.contract.ConfirmPaymentReceived(l.as(testEmployee), "11930", "0"))
+	l.ok(l.contract.RejectPayment(l.as(testEmployee), "11930", "1", "Never arrived"))
+	l.ok(l.contract.ConfirmPaymentReceived(l.as(testEmployee), "11930", "3"))
+
+	_, err := l.contract.ConfirmPaymentReceived(l.as(testEmployee), "11930", "1")
+	wantError(t, err, "The payment 1 was already rejected")
+	_, err = l.contract.RejectPayment(l.as(testEmployee), "11930", "0", "Wrong amount")
+	wantError(t, err, "already confirmed")
+	if name, _ := l.lastEvent(); name != "" {
+		t.Fatalf("a refused transaction emitted %s", name)
+	}
+
+	sar := func(major int64) Money { return Money{MinorUnits: major * 100, Currency: "SAR"} }
+	tests := []struct {
+		today   string
+		periods int
+		want    ArrearsReport
+	}{
+		{"01/31/2026", 0, ArrearsReport{TotalExpected: sar(0), TotalPaid: sar(0), TotalConfirmed: sar(0),
+			TotalDisputed: sar(0), TotalRejected: sar(0), TotalArrears: sar(0)}},
+		{"02/01/2026", 1, ArrearsReport{TotalExpected: sar(10000), TotalPaid: sar(10000), TotalConfirmed: sar(10000),
+			TotalDisputed: sar(0), TotalRejected: sar(0), TotalArrears: sar(0)}},
+		{"04/15/2026", 3, ArrearsReport{TotalExpected: sar(30000), TotalPaid: sar(30000), TotalConfirmed: sar(14000),
+			TotalDisputed: sar(6000), TotalRejected: sar(10000), TotalArrears: sar(16000)}},
+		{"05/01/2026", 4, ArrearsReport{TotalExpected: sar(40000), TotalPaid: sar(30000), TotalConfirmed: sar(14000),
+			TotalDisputed: sar(6000), TotalRejected: sar(10000), TotalArrears: sar(26000)}},
+	}
+	for _, test := range tests {
+		t.Run(test.today, func(t *testing.T) {
+			l.setDate(test.today)
+			report, err := l.contract.GetArrears(l.as(testAuthorityA), "11930")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Periods) != test.periods {
+				t.Fatalf("want %d periods, got %+v", test.periods, report.Periods)
+			}
+			got := *report
+			got.Periods = nil
+			test.want.ContractID, test.want.Currency = "11930", "SAR"
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("want %+v, got %+v", test.want, got)
+			}
+		})
+	}
+
+	l.setDate("04/15/2026")
+	report, err := l.contract.GetArrears(l.as(testEmployee), "11930")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PeriodArrears{
+		{"01/2026", sar(10000), sar(10000), sar(10000), sar(0), sar(0), sar(0)},
+		{"02/2026", sar(10000), sar(10000), sar(0), sar(0), sar(10000), sar(10000)},
+		{"03/2026", sar(10000), sar(10000), sar(4000), sar(6000), sar(0), sar(6000)},
+	}
+	for i, period := range report.Periods {
+		if period != want[i] {
+			t.Fatalf("want %+v, got %+v", want[i], period)
+		}
+	}
+}
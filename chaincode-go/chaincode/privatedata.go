@@ -0,0 +1,156 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// The private data collection that holds the salary and contact details of every contract, and the employee identities.
+// It is shared by the countries' orgs, see collections_config.json.
+const privateCollection = "contractPrivateDetails"
+
+// The transient map key HandleAddContract and UpdateContract read the private details from.
+const privateTransientKey = "contract_private"
+
+// ContractPrivateDetails: the sensitive fields of a contract. They are kept in privateCollection instead of the world state,
+// and only their hash is stored with the contract.
+type ContractPrivateDetails struct {
+	Salary     Money  `json:"Salary"`
+	Housing    Money  `json:"Housing"`
+	Allowances Money  `json:"Allowances"`
+	EmployerAC string `json:"Employer address and contact details"`
+	EmployeeAC string `json:"Employee address and contact details"`
+	Salt       string `json:"Salt"` // A random value from the client, so the hash can't be guessed from common salaries.
+	// EmployeeID and EmployeeName are the cleartext ID and name of the employee, who is known by a pseudonym in the world state.
+	// Both are missing from private details written before pseudonyms were added, see pseudonym.go.
+	EmployeeID   string `json:"Employee ID,omitempty" metadata:"Employee ID,optional"`
+	EmployeeName string `json:"Employee name,omitempty" metadata:"Employee name,optional"`
+}
+
+// readPrivateDetails reads the private details from the transient map. They are never passed as arguments,
+// because arguments are written to the block.
+func readPrivateDetails(ctx contractapi.TransactionContextInterface) (*ContractPrivateDetails, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the transient map: %v", err)
+	}
+	privateJSON, ok := transientMap[privateTransientKey]
+	if !ok {
+		return nil, fmt.Errorf("the private details must be passed in the transient map under %s", privateTransientKey)
+	}
+
+	var private ContractPrivateDetails
+	if err := json.Unmarshal(privateJSON, &private); err != nil {
+		return nil, fmt.Errorf("Error Unmarshaling private details: %s", err)
+	}
+	if private.Salt == "" {
+		return nil, fmt.Errorf("the private details must have a Salt")
+	}
+	if err := private.Salary.checkArgument("Salary"); err != nil {
+		return nil, err
+	}
+	if err := private.Housing.checkArgument("Housing"); err != nil {
+		return nil, err
+	}
+	if err := private.Allowances.checkArgument("Allowances"); err != nil {
+		return nil, err
+	}
+	return &private, nil
+}
+
+// Will return the hex SHA-256 of the private details. It is stored in the world state so both parties can prove what was agreed.
+func (p *ContractPrivateDetails) hash() (string, error) {
+	privateJSON, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(privateJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Will copy the private details into the contract. Amounts without a currency, from older records,
+// are migrated to the currency of the contract first. Those from the transient map have one, see readPrivateDetails.
+func mergePrivateDetails(contract *Contract, private *ContractPrivateDetails) {
+	private.Salary.migrate(contract.Benefits.Currency)
+	private.Housing.migrate(contract.Benefits.Currency)
+	private.Allowances.migrate(contract.Benefits.Currency)
+	contract.Benefits.Salary = private.Salary
+	contract.Benefits.Housing = private.Housing
+	contract.Benefits.Allowances = private.Allowances
+	contract.Employer.EmployerAC = private.EmployerAC
+	contract.Employee.EmployeeAC = private.EmployeeAC
+	// Contracts written before pseudonyms were added keep the employee name in the world state.
+	if private.EmployeeID != "" {
+		contract.Employee.Identifier = private.EmployeeID
+	}
+	if private.EmployeeName != "" {
+		contract.Employee.Name = private.EmployeeName
+	}
+}
+
+// Will clear the private details from the contract before it is written to the world state.
+func stripPrivateDetails(contract *Contract) {
+	mergePrivateDetails(contract, &ContractPrivateDetails{})
+	contract.Employee.Identifier = ""
+	contract.Employee.Name = ""
+}
+
+// putPrivateDetails writes the private details of the contract to privateCollection, and sets the contract hash.
+// @Param from is the date the salary applies from, so GetArrears can tell what was due before the change.
+func putPrivateDetails(ctx contractapi.TransactionContextInterface, contract *Contract, private *ContractPrivateDetails, from string) error {
+	hash, err := private.hash()
+	if err != nil {
+		return err
+	}
+	privateJSON, err := json.Marshal(private)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollection, contract.ID, privateJSON); err != nil {
+		return fmt.Errorf("failed to write the private details: %v", err)
+	}
+	contract.PrivateDetailsHash = hash
+	return recordSalaryChange(ctx, contract.ID, from, private.Salary)
+}
+
+// loadPrivateDetails merges the private details and the employee identity into the contract if the caller is allowed to see them.
+// Contracts created before private data was added have no private details and are left as they are.
+func loadPrivateDetails(ctx contractapi.TransactionContextInterface, contract *Contract) error {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if !caller.can("ReadPrivateDetails", contract) {
+		return nil
+	}
+
+	private, err := getPrivateDetails(ctx, contract.ID)
+	if err != nil {
+		return err
+	}
+	if private != nil {
+		mergePrivateDetails(contract, private)
+	}
+	return loadEmployeeIdentity(ctx, contract)
+}
+
+// getPrivateDetails returns the private details of the contract from privateCollection, without checking the caller.
+// Will return nil if the contract has none.
+func getPrivateDetails(ctx contractapi.TransactionContextInterface, ID string) (*ContractPrivateDetails, error) {
+	privateJSON, err := ctx.GetStub().GetPrivateData(privateCollection, ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the private details: %v", err)
+	}
+	if privateJSON == nil {
+		return nil, nil
+	}
+	var private ContractPrivateDetails
+	if err := json.Unmarshal(privateJSON, &private); err != nil {
+		return nil, err
+	}
+	return &private, nil
+}
@@ -0,0 +1,445 @@
+package chaincode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Employees are known on the shared ledger by a pseudonym instead of their ID, which is often a passport or national ID.
+// The pseudonym is the HMAC-SHA256 of the ID keyed with a secret salt. The salt and the cleartext ID and name of every
+// employee are only kept in privateCollection, so only the countries' orgs can tell who a pseudonym stands for.
+const (
+	pseudonymSaltKeyType = "pseudonym~salt"    // No attributes. Holds the salt.
+	identityKeyType      = "employee~identity" // pseudonym. Holds the EmployeeIdentity.
+	pseudonymPrefix      = "emp-"
+)
+
+// The transient map keys SetPseudonymSalt reads the salt from, and RegisterEmployee and UpdateParty read the EmployeeIdentity from.
+const (
+	saltTransientKey     = "pseudonym_salt"
+	identityTransientKey = "employee_identity"
+)
+
+// The salt must be long enough that the pseudonyms of known IDs can't be guessed.
+const minSaltLength = 16
+
+// EmployeeIdentity: the cleartext ID and name of an employee. Kept in privateCollection under the employee's pseudonym.
+type EmployeeIdentity struct {
+	ID   string `json:"ID"`
+	Name string `json:"Name"`
+}
+
+// Will return true if the ID is already a pseudonym.
+func isPseudonym(ID string) bool {
+	digest, err := hex.DecodeString(strings.TrimPrefix(ID, pseudonymPrefix))
+	return strings.HasPrefix(ID, pseudonymPrefix) && err == nil && len(digest) == sha256.Size
+}
+
+func pseudonymSaltKey(ctx contractapi.TransactionContextInterface) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(pseudonymSaltKeyType, []string{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", pseudonymSaltKeyType, err)
+	}
+	return key, nil
+}
+
+func identityKey(ctx contractapi.TransactionContextInterface, pseudonym string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(identityKeyType, []string{pseudonym})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", identityKeyType, err)
+	}
+	return key, nil
+}
+
+// readPseudonymSalt returns the salt. Will return nil if no authority has set it yet.
+func readPseudonymSalt(ctx contractapi.TransactionContextInterface) ([]byte, error) {
+	key, err := pseudonymSaltKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := ctx.GetStub().GetPrivateData(privateCollection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the pseudonym salt: %v", err)
+	}
+	return salt, nil
+}
+
+// employeePseudonym returns the pseudonym of the employee ID. An ID that is already a pseudonym is returned as it is.
+// Will return an error if the salt has not been set.
+func employeePseudonym(ctx contractapi.TransactionContextInterface, ID string) (string, error) {
+	if isPseudonym(ID) {
+		return ID, nil
+	}
+	salt, err := readPseudonymSalt(ctx)
+	if err != nil {
+		return "", err
+	}
+	if salt == nil {
+		return "", fmt.Errorf("pseudonym salt not set. An authority must run SetPseudonymSalt first")
+	}
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(ID))
+	return pseudonymPrefix + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// lookupPartyID returns the ID the caller names a party by on the ledger. It is the ID itself for employers, and the pseudonym for employees.
+// Only the employee themselves and the authorities of their country can name an employee by their cleartext ID, so no one else can tell
+// which pseudonym belongs to whom. The error is the same for an employee who is not registered, and does not give the pseudonym away.
+func lookupPartyID(ctx contractapi.TransactionContextInterface, caller Caller, role string, ID string) (string, error) {
+	if role != RoleEmployee || isPseudonym(ID) {
+		return ID, nil
+	}
+	pseudonym, err := employeePseudonym(ctx, ID)
+	if err != nil {
+		return "", err
+	}
+	if caller.Role == RoleEmployee && caller.PartyID == pseudonym {
+		return pseudonym, nil
+	}
+	if caller.Role == RoleAuthority {
+		party, err := readParty(ctx, RoleEmployee, pseudonym)
+		if err != nil {
+			return "", err
+		}
+		if party != nil && caller.MSPID == countryMSP(party.Country) {
+			return pseudonym, nil
+		}
+	}
+	return "", fmt.Errorf("caller with role %s from %s can not name an employee by their cleartext ID. Only the employee and the authorities "+
+		"of their country can, everyone else must pass the pseudonym", caller.Role, caller.MSPID)
+}
+
+// readIdentityTransient reads the cleartext ID and name of an employee from the transient map, so they are not written to the block.
+func readIdentityTransient(ctx contractapi.TransactionContextInterface) (*EmployeeIdentity, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the transient map: %v", err)
+	}
+	identityJSON, ok := transientMap[identityTransientKey]
+	if !ok {
+		return nil, fmt.Errorf("the employee ID and name must be passed in the transient map under %s", identityTransientKey)
+	}
+	var identity EmployeeIdentity
+	if err := json.Unmarshal(identityJSON, &identity); err != nil {
+		return nil, fmt.Errorf("Error Unmarshaling employee identity: %s", err)
+	}
+	identity.ID, identity.Name = strings.TrimSpace(identity.ID), strings.TrimSpace(identity.Name)
+	if identity.ID == "" || identity.Name == "" {
+		return nil, fmt.Errorf("the ID and the name of the employee can not be empty")
+	}
+	if isPseudonym(identity.ID) {
+		return nil, fmt.Errorf("the cleartext employee ID must be passed, not its pseudonym")
+	}
+	return &identity, nil
+}
+
+// getEmployeeIdentity returns the cleartext ID and name behind the pseudonym. Will return nil if they are not known.
+func getEmployeeIdentity(ctx contractapi.TransactionContextInterface, pseudonym string) (*EmployeeIdentity, error) {
+	key, err := identityKey(ctx, pseudonym)
+	if err != nil {
+		return nil, err
+	}
+	identityJSON, err := ctx.GetStub().GetPrivateData(privateCollection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the employee identity: %v", err)
+	}
+	if identityJSON == nil {
+		return nil, nil
+	}
+	var identity EmployeeIdentity
+	if err := json.Unmarshal(identityJSON, &identity); err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// putEmployeeIdentity writes the cleartext ID and name of the employee to privateCollection under their pseudonym.
+func putEmployeeIdentity(ctx contractapi.TransactionContextInterface, pseudonym string, identity *EmployeeIdentity) error {
+	key, err := identityKey(ctx, pseudonym)
+	if err != nil {
+		return err
+	}
+	identityJSON, err := json.Marshal(identity)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollection, key, identityJSON); err != nil {
+		return fmt.Errorf("failed to write the employee identity: %v", err)
+	}
+	return nil
+}
+
+// checkArgumentEmployee will return an error if the contract passed as an argument holds the employee name or cleartext ID.
+// Arguments are written to the block, so both are only taken from the private details.
+func checkArgumentEmployee(contract *Contract) error {
+	if contract.Employee.Name != "" || contract.Employee.Identifier != "" {
+		return fmt.Errorf("the employee name and cleartext ID must be left out of the contract and passed in the private details")
+	}
+	return nil
+}
+
+// pseudonymizeEmployee replaces the employee ID of the contract with its pseudonym. The cleartext ID comes from the private details,
+// since the contract itself is passed as an argument and written to the block.
+func pseudonymizeEmployee(ctx contractapi.TransactionContextInterface, contract *Contract, private *ContractPrivateDetails) error {
+	if private.EmployeeID == "" || isPseudonym(private.EmployeeID) {
+		return fmt.Errorf("the cleartext employee ID must be passed in the private details")
+	}
+	pseudonym, err := employeePseudonym(ctx, private.EmployeeID)
+	if err != nil {
+		return err
+	}
+	if contract.Employee.ID != "" && contract.Employee.ID != pseudonym {
+		return fmt.Errorf("the employee ID of the contract must be left empty or be the pseudonym of the one in the private details")
+	}
+	contract.Employee.ID = pseudonym
+	return nil
+}
+
+// loadEmployeeIdentity fills the cleartext ID and name of the employee for contracts whose private details don't hold them,
+// because they were written before pseudonyms were added.
+func loadEmployeeIdentity(ctx contractapi.TransactionContextInterface, contract *Contract) error {
+	if contract.Employee.Identifier != "" || !isPseudonym(contract.Employee.ID) {
+		return nil
+	}
+	identity, err := getEmployeeIdentity(ctx, contract.Employee.ID)
+	if err != nil || identity == nil {
+		return err
+	}
+	contract.Employee.Identifier = identity.ID
+	if contract.Employee.Name == "" {
+		contract.Employee.Name = identity.Name
+	}
+	return nil
+}
+
+/*
+* This method will set the salt employee pseudonyms are computed with. The salt is read from the transient map under
+* pseudonym_salt and must be at least 16 random bytes. It can only be set once, since every pseudonym on the ledger depends on it.
+* Only authorities can run it.
+ */
+func (s *SmartContract) SetPseudonymSalt(ctx contractapi.TransactionContextInterface) (bool, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	if caller.Role != RoleAuthority {
+		return false, fmt.Errorf("access denied: caller with role %s from %s can not call SetPseudonymSalt", caller.Role, caller.MSPID)
+	}
+	existing, err := readPseudonymSalt(ctx)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, fmt.Errorf("the pseudonym salt is already set and can't be changed")
+	}
+
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return false, fmt.Errorf("failed to read the transient map: %v", err)
+	}
+	salt, ok := transientMap[saltTransientKey]
+	if !ok {
+		return false, fmt.Errorf("the salt must be passed in the transient map under %s", saltTransientKey)
+	}
+	if len(salt) < minSaltLength {
+		return false, fmt.Errorf("the salt must be at least %d bytes", minSaltLength)
+	}
+	key, err := pseudonymSaltKey(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollection, key, salt); err != nil {
+		return false, fmt.Errorf("failed to write the pseudonym salt: %v", err)
+	}
+	return true, nil
+}
+
+/*
+* This method will replace the cleartext employee IDs written before pseudonyms were added, and return how many contracts it changed.
+* The registered employees, their signing keys, the contracts, their amendments, and the employee~contract index are moved to the pseudonyms,
+* and the names are moved to privateCollection. The signatures on the contracts are cleared, since their terms change,
+* and Pending contracts must be signed again before they can be approved.
+* It is only needed once, after SetPseudonymSalt. Only authorities can run it.
+ */
+func (s *SmartContract) PseudonymizeEmployees(ctx contractapi.TransactionContextInterface) (int, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if caller.Role != RoleAuthority {
+		return 0, fmt.Errorf("access denied: caller with role %s from %s can not call PseudonymizeEmployees", caller.Role, caller.MSPID)
+	}
+
+	// The registry goes first, so the names it holds win over the ones in older contracts.
+	if err := pseudonymizeParties(ctx); err != nil {
+		return 0, err
+	}
+	if err := pseudonymizeSigningKeys(ctx); err != nil {
+		return 0, err
+	}
+
+	contracts, err := s.getAllContracts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	for _, contract := range contracts {
+		if isPseudonym(contract.Employee.ID) {
+			continue
+		}
+		if err := pseudonymizeContract(ctx, contract); err != nil {
+			return 0, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// pseudonymizeParties moves every employee registered under a cleartext ID to their pseudonym.
+func pseudonymizeParties(ctx contractapi.TransactionContextInterface) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(partyKeyType, []string{RoleEmployee})
+	if err != nil {
+		return err
+	}
+	// The records are collected first, since the loop below writes to the same key range.
+	var parties []Party
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			resultsIterator.Close()
+			return err
+		}
+		var party Party
+		if err := json.Unmarshal(queryResponse.Value, &party); err != nil {
+			resultsIterator.Close()
+			return err
+		}
+		if !isPseudonym(party.ID) {
+			parties = append(parties, party)
+		}
+	}
+	resultsIterator.Close()
+
+	for _, party := range parties {
+		pseudonym, err := employeePseudonym(ctx, party.ID)
+		if err != nil {
+			return err
+		}
+		if err := putEmployeeIdentity(ctx, pseudonym, &EmployeeIdentity{ID: party.ID, Name: party.Name}); err != nil {
+			return err
+		}
+		oldKey, err := partyKey(ctx, RoleEmployee, party.ID)
+		if err != nil {
+			return err
+		}
+		if err := ctx.GetStub().DelState(oldKey); err != nil {
+			return err
+		}
+		party.ID, party.Name = pseudonym, ""
+		if err := putParty(ctx, &party); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pseudonymizeSigningKeys moves the signing key of every employee registered under a cleartext ID to their pseudonym.
+func pseudonymizeSigningKeys(ctx contractapi.TransactionContextInterface) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(signingKeyType, []string{RoleEmployee})
+	if err != nil {
+		return err
+	}
+	var signingKeys []SigningKey
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			resultsIterator.Close()
+			return err
+		}
+		var signingKey SigningKey
+		if err := json.Unmarshal(queryResponse.Value, &signingKey); err != nil {
+			resultsIterator.Close()
+			return err
+		}
+		if !isPseudonym(signingKey.PartyID) {
+			signingKeys = append(signingKeys, signingKey)
+		}
+	}
+	resultsIterator.Close()
+
+	for _, signingKey := range signingKeys {
+		oldKey, err := signingKeyKey(ctx, RoleEmployee, signingKey.PartyID)
+		if err != nil {
+			return err
+		}
+		if err := ctx.GetStub().DelState(oldKey); err != nil {
+			return err
+		}
+		signingKey.PartyID, err = employeePseudonym(ctx, signingKey.PartyID)
+		if err != nil {
+			return err
+		}
+		newKey, err := signingKeyKey(ctx, RoleEmployee, signingKey.PartyID)
+		if err != nil {
+			return err
+		}
+		signingKeyJSON, err := json.Marshal(signingKey)
+		if err != nil {
+			return err
+		}
+		if err := ctx.GetStub().PutState(newKey, signingKeyJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pseudonymizeContract moves the contract, its amendments, and its index key to the pseudonym of its employee.
+// The name is kept in privateCollection unless the registry already holds one for the employee.
+func pseudonymizeContract(ctx contractapi.TransactionContextInterface, contract *Contract) error {
+	cleartextID := contract.Employee.ID
+	pseudonym, err := employeePseudonym(ctx, cleartextID)
+	if err != nil {
+		return err
+	}
+	identity, err := getEmployeeIdentity(ctx, pseudonym)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		if err := putEmployeeIdentity(ctx, pseudonym, &EmployeeIdentity{ID: cleartextID, Name: contract.Employee.Name}); err != nil {
+			return err
+		}
+	}
+
+	contract.Employee.ID, contract.Employee.Name = pseudonym, ""
+	// The signatures are over the cleartext terms, so they no longer match and both parties must sign again.
+	contract.Signatures = []Signature{}
+	amendments, err := listAmendments(ctx, contract.ID)
+	if err != nil {
+		return err
+	}
+	for _, amendment := range amendments {
+		amendment.Terms.Employee.ID, amendment.Terms.Employee.Name = pseudonym, ""
+		if amendment.Base != nil {
+			amendment.Base.Employee.ID, amendment.Base.Employee.Name = pseudonym, ""
+		}
+		if err := putAmendment(ctx, &amendment); err != nil {
+			return err
+		}
+	}
+
+	if err := putContract(ctx, contract); err != nil {
+		return err
+	}
+	if err := deleteIndexKey(ctx, employeeIndex, cleartextID, contract.ID); err != nil {
+		return err
+	}
+	return putIndexKey(ctx, employeeIndex, pseudonym, contract.ID)
+}
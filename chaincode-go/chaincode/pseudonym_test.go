@@ -0,0 +1,64 @@
+package chaincode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+func TestCallerPseudonym(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+	l.addContract("11930", "01/02/2026", "05/05/2028")
+	pseudonym := l.read("11930").Employee.ID
+	enrolledByPseudonym := testIdentity{"CountryBMSP", RoleEmployee, pseudonym}
+
+	// On a peer outside the collection, the salt can't be read.
+	l.stub.outsideCollection = true
+	_, err := l.contract.GetDisputes(l.as(testEmployee), "11930", "")
+	wantError(t, err, "the partyID attribute of the caller holds the cleartext employee ID, which can't be pseudonymized: failed to read the pseudonym salt")
+	if _, err := l.contract.GetDisputes(l.as(enrolledByPseudonym), "11930", ""); err != nil {
+		t.Fatalf("want the employee enrolled by pseudonym to read the disputes without the salt, got %v", err)
+	}
+	l.stub.outsideCollection = false
+
+	// Before any authority set the salt.
+	unset := &testLedger{t: t, stub: &testStub{MockStub: shimtest.NewMockStub("contracts", nil)}, contract: &SmartContract{}}
+	_, err = getCaller(unset.as(testEmployee))
+	wantError(t, err, "pseudonym salt not set")
+	caller, err := getCaller(unset.as(enrolledByPseudonym))
+	if err != nil || caller.PartyID != pseudonym {
+		t.Fatalf("want the caller %s, got %+v, %v", pseudonym, caller, err)
+	}
+}
+
+func TestEmployeeNameArgument(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace string
+		wantErr string
+	}{
+		{"no name", "'Name': ''", ""},
+		{"name", "'Name': 'John Doe'", "the employee name and cleartext ID must be left out of the contract"},
+		{"cleartext ID", "'Name': '','Identifier': '44110'", "the employee name and cleartext ID must be left out of the contract"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLedger(t, "01/01/2026")
+			contractJSON := strings.Replace(testContractJSON, "'Employee': {'ID': '','Name': ''", "'Employee': {'ID': '',"+test.replace, 1)
+			_, err := l.contract.HandleAddContract(l.as(testEmployer), contractJSON)
+			wantError(t, err, test.wantErr)
+			if test.wantErr != "" {
+				return
+			}
+			// The name is taken from the registry, and only kept in the private collection.
+			if name := l.read("11930").Employee.Name; name != "John Doe" {
+				t.Fatalf("want the employee name from the registry, got %q", name)
+			}
+			stateJSON, err := l.stub.GetState("11930")
+			if err != nil || strings.Contains(string(stateJSON), "John Doe") {
+				t.Fatalf("want the world state without the employee name, got %s, %v", stateJSON, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ContractPage: one page of contracts with the bookmark to fetch the next page.
+type ContractPage struct {
+	Records             []*Contract `json:"Records"`
+	FetchedRecordsCount int         `json:"Fetched records count"` // The number of Records returned.
+	ScannedRecordsCount int         `json:"Scanned records count"` // The number of records the page read. Fewer than the page size on the last page.
+	Bookmark            string      `json:"Bookmark"`
+}
+
+/*
+* Rich queries need the peers to run CouchDB. See fablo-config-couchdb.json.
+* @Param selectorJSON is a CouchDB selector, for example {"Status":"Active","Employer.ID":"Comp-1"}.
+* A full query such as {"selector":{...},"sort":[...]} is accepted as well.
+* Employee.ID is matched against the pseudonym. The employee themselves and the authorities of their country can pass the cleartext ID instead.
+* Only the contracts the caller can read with ReadContract are returned.
+ */
+func (s *SmartContract) QueryContracts(ctx contractapi.TransactionContextInterface, selectorJSON string) ([]*Contract, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query, err := buildQuery(ctx, caller, selectorJSON)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, err := ctx.GetStub().GetQueryResult(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run the query: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	return readableContracts(ctx, caller, resultsIterator)
+}
+
+// QueryContractsWithPagination is like QueryContracts, but returns at most pageSize contracts starting from the given bookmark.
+// Pass an empty bookmark to get the first page. The page can hold fewer records than it read, as the ones the caller can not read are dropped,
+// so keep fetching until Scanned records count is less than the page size.
+func (s *SmartContract) QueryContractsWithPagination(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int, bookmark string) (*ContractPage, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query, err := buildQuery(ctx, caller, selectorJSON)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize < 1 {
+		return nil, fmt.Errorf("the page size must be at least 1")
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(query, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run the query: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	contracts, err := readableContracts(ctx, caller, resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractPage{
+		Records:             contracts,
+		FetchedRecordsCount: len(contracts),
+		ScannedRecordsCount: int(metadata.FetchedRecordsCount),
+		Bookmark:            metadata.Bookmark,
+	}, nil
+}
+
+// buildQuery turns the selector into a CouchDB query string. Single quotes are accepted in place of double quotes, like in HandleAddContract.
+// The Employee IDs the selector matches on are replaced by their pseudonyms, see pseudonymizeSelector.
+func buildQuery(ctx contractapi.TransactionContextInterface, caller Caller, selectorJSON string) (string, error) {
+	selectorJSON = strings.ReplaceAll(selectorJSON, "'", "\"")
+	var selector map[string]interface{}
+	if err := json.Unmarshal([]byte(selectorJSON), &selector); err != nil {
+		return "", fmt.Errorf("Error Unmarshaling selector: %s, \n %s", err, selectorJSON)
+	}
+	if _, ok := selector["selector"]; !ok {
+		selector = map[string]interface{}{"selector": selector}
+	}
+	if inner, ok := selector["selector"].(map[string]interface{}); ok {
+		if err := pseudonymizeSelector(ctx, caller, inner, ""); err != nil {
+			return "", err
+		}
+	}
+	query, err := json.Marshal(selector)
+	if err != nil {
+		return "", err
+	}
+	return string(query), nil
+}
+
+// pseudonymizeSelector replaces the Employee IDs a selector matches on with the pseudonyms the world state holds, through lookupPartyID.
+// It follows both the "Employee.ID" and the {"Employee":{"ID":...}} forms, and the $and, $or, $nor, and $not combinations.
+// @Param path is the field the selector is nested under, and empty at the top.
+func pseudonymizeSelector(ctx contractapi.TransactionContextInterface, caller Caller, selector map[string]interface{}, path string) error {
+	for field, value := range selector {
+		switch field {
+		case "$and", "$or", "$nor":
+			conditions, _ := value.([]interface{})
+			for _, condition := range conditions {
+				if nested, ok := condition.(map[string]interface{}); ok {
+					if err := pseudonymizeSelector(ctx, caller, nested, path); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		case "$not":
+			if nested, ok := value.(map[string]interface{}); ok {
+				if err := pseudonymizeSelector(ctx, caller, nested, path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(field, "$") {
+			continue // Other operators don't name a field.
+		}
+		fieldPath := field
+		if path != "" {
+			fieldPath = path + "." + field
+		}
+		if fieldPath == "Employee.ID" {
+			mapped, err := pseudonymizeCondition(ctx, caller, value)
+			if err != nil {
+				return err
+			}
+			selector[field] = mapped
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if err := pseudonymizeSelector(ctx, caller, nested, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pseudonymizeCondition maps the Employee IDs of one condition: a plain value, or the values of its $eq, $ne, $in, and $nin operators.
+// Other operators, such as $exists or $regex, are left as they are.
+func pseudonymizeCondition(ctx contractapi.TransactionContextInterface, caller Caller, condition interface{}) (interface{}, error) {
+	switch condition := condition.(type) {
+	case string:
+		return lookupPartyID(ctx, caller, RoleEmployee, condition)
+	case map[string]interface{}:
+		for operator, value := range condition {
+			switch operator {
+			case "$eq", "$ne", "$not":
+				mapped, err := pseudonymizeCondition(ctx, caller, value)
+				if err != nil {
+					return nil, err
+				}
+				condition[operator] = mapped
+			case "$in", "$nin":
+				values, _ := value.([]interface{})
+				for i, value := range values {
+					mapped, err := pseudonymizeCondition(ctx, caller, value)
+					if err != nil {
+						return nil, err
+					}
+					values[i] = mapped
+				}
+			}
+		}
+	}
+	return condition, nil
+}
+
+// readableContracts reads the contracts from a query and drops the ones the caller is not allowed to read.
+func readableContracts(ctx contractapi.TransactionContextInterface, caller Caller, resultsIterator shim.StateQueryIteratorInterface) ([]*Contract, error) {
+	contracts := []*Contract{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		// Contracts use simple keys. Anything under a composite key is another kind of record.
+		if strings.HasPrefix(queryResponse.Key, "\x00") {
+			continue
+		}
+
+		var contract Contract
+		if err := json.Unmarshal(queryResponse.Value, &contract); err != nil {
+			return nil, err
+		}
+		if !caller.can("ReadContract", &contract) {
+			continue
+		}
+		if err := loadDisputes(ctx, &contract); err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, &contract)
+	}
+	return contracts, nil
+}
+
+// GetAllContractsWithPagination returns at most pageSize contracts starting from the given bookmark. Only authorities can list every contract.
+// Pass an empty bookmark to get the first page. It works on both LevelDB and CouchDB.
+func (s *SmartContract) GetAllContractsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int, bookmark string) (*ContractPage, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if caller.Role != RoleAuthority {
+		return nil, fmt.Errorf("access denied: caller with role %s from %s can not call GetAllContractsWithPagination", caller.Role, caller.MSPID)
+	}
+	if pageSize < 1 {
+		return nil, fmt.Errorf("the page size must be at least 1")
+	}
+
+	// Like GetAllContracts, empty start and end keys cover every contract but skip composite keys.
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination("", "", int32(pageSize), bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	contracts := []*Contract{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var contract Contract
+		if err := json.Unmarshal(queryResponse.Value, &contract); err != nil {
+			return nil, err
+		}
+		if err := loadDisputes(ctx, &contract); err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, &contract)
+	}
+
+	return &ContractPage{
+		Records:             contracts,
+		FetchedRecordsCount: len(contracts),
+		ScannedRecordsCount: int(metadata.FetchedRecordsCount),
+		Bookmark:            metadata.Bookmark,
+	}, nil
+}
@@ -0,0 +1,372 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Registered employers and employees are stored under this composite key object type.
+const partyKeyType = "party~record" // role, party ID
+
+// PartyStatus: where a registered party is in onboarding. Only Verified parties can be named in a new contract.
+type PartyStatus string
+
+const (
+	PartyPending  PartyStatus = "Pending"  // Registered or changed, and waiting for an authority of its country.
+	PartyVerified PartyStatus = "Verified" // An authority of its country checked its details.
+	PartyRejected PartyStatus = "Rejected" // An authority of its country refused its details, or revoked its verification.
+)
+
+// Party: an employer or an employee as registered on the ledger. Contracts take the name and the country from here,
+// so the same ID always stands for the same party. Contact details stay in the private details of each contract.
+// Employees are registered under their pseudonym, and their name is only kept in the private collection, see pseudonym.go.
+type Party struct {
+	Role           string      `json:"Role"` // Either employer or employee.
+	ID             string      `json:"ID"`   // The Employer ID, or the pseudonym of the Employee ID, contracts refer to.
+	Name           string      `json:"Name"`
+	Country        string      `json:"Country"`
+	Status         PartyStatus `json:"Status"`
+	Revision       int         `json:"Revision"`      // Goes up by one with every change, see checkRevision.
+	RegisteredBy   string      `json:"Registered by"` // The MSP ID of the identity that registered the party.
+	RegisteredDate string      `json:"Registered date"`
+	// StatusBy is the MSP ID of the authority that last verified or rejected the party, and StatusDate when.
+	StatusBy   string `json:"Status by,omitempty" metadata:"Status by,optional"`
+	StatusDate string `json:"Status date,omitempty" metadata:"Status date,optional"`
+}
+
+func partyKey(ctx contractapi.TransactionContextInterface, role string, ID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(partyKeyType, []string{role, ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", partyKeyType, err)
+	}
+	return key, nil
+}
+
+// readParty returns the registered party. Will return nil if the party is not registered.
+func readParty(ctx contractapi.TransactionContextInterface, role string, ID string) (*Party, error) {
+	key, err := partyKey(ctx, role, ID)
+	if err != nil {
+		return nil, err
+	}
+	partyJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if partyJSON == nil {
+		return nil, nil
+	}
+	var party Party
+	if err := json.Unmarshal(partyJSON, &party); err != nil {
+		return nil, err
+	}
+	return &party, nil
+}
+
+// putParty increases the party revision and writes it to the world state.
+func putParty(ctx contractapi.TransactionContextInterface, party *Party) error {
+	key, err := partyKey(ctx, party.Role, party.ID)
+	if err != nil {
+		return err
+	}
+	party.Revision++
+	partyJSON, err := json.Marshal(party)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, partyJSON)
+}
+
+// Will return true if the caller is the party itself, or an authority of the party's country.
+// Like Caller.is, the party itself must call from the MSP of its country.
+func (c Caller) manages(party *Party) bool {
+	if c.MSPID != countryMSP(party.Country) {
+		return false
+	}
+	return (c.Role == party.Role && c.PartyID == party.ID) || c.Role == RoleAuthority
+}
+
+// partyLabel names the party in error messages. Employees are not named, so an error never gives away the pseudonym of a cleartext ID.
+func partyLabel(role string, ID string) string {
+	if role == RoleEmployee {
+		return "the employee"
+	}
+	return fmt.Sprintf("the %s %s", role, ID)
+}
+
+// resolveParty returns the verified registered party. Will return an error if it is not registered, not Verified,
+// or if the name or the country given for it differ from the registry. Empty ones are taken from the registry.
+func resolveParty(ctx contractapi.TransactionContextInterface, role string, ID string, name string, country string) (*Party, error) {
+	party, err := readParty(ctx, role, ID)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil {
+		return nil, fmt.Errorf("%s is not registered. It must be registered and verified first", partyLabel(role, ID))
+	}
+	if party.Status != PartyVerified {
+		return nil, fmt.Errorf("%s is %s. Only Verified parties can be named in a contract", partyLabel(role, ID), party.Status)
+	}
+	if role == RoleEmployee {
+		if err := loadPartyName(ctx, party); err != nil {
+			return nil, err
+		}
+	}
+	if name != "" && name != party.Name {
+		return nil, fmt.Errorf("%s is registered as %s, not %s", partyLabel(role, ID), party.Name, name)
+	}
+	if country != "" && country != party.Country {
+		return nil, fmt.Errorf("%s is registered in %s, not %s", partyLabel(role, ID), party.Country, country)
+	}
+	return party, nil
+}
+
+// resolveParties fills the name and the country of the employer and the employee of the contract from the registry.
+// Will return an error unless both are registered and Verified, see resolveParty. The employee ID is replaced with its pseudonym
+// first, and the employee name is kept with the other private details.
+func resolveParties(ctx contractapi.TransactionContextInterface, contract *Contract, private *ContractPrivateDetails) error {
+	if err := pseudonymizeEmployee(ctx, contract, private); err != nil {
+		return err
+	}
+	employer, err := resolveParty(ctx, RoleEmployer, contract.Employer.ID, contract.Employer.Name, contract.Employer.Country)
+	if err != nil {
+		return err
+	}
+	employee, err := resolveParty(ctx, RoleEmployee, contract.Employee.ID, contract.Employee.Name, contract.Employee.Country)
+	if err != nil {
+		return err
+	}
+	contract.Employer.Name, contract.Employer.Country = employer.Name, employer.Country
+	contract.Employee.Name, contract.Employee.Country = employee.Name, employee.Country
+	private.EmployeeName = employee.Name
+	return nil
+}
+
+// loadPartyName fills the name of a registered employee from the private collection.
+func loadPartyName(ctx contractapi.TransactionContextInterface, party *Party) error {
+	identity, err := getEmployeeIdentity(ctx, party.ID)
+	if err != nil {
+		return err
+	}
+	if identity == nil {
+		return fmt.Errorf("the name of the employee is missing from the private collection")
+	}
+	party.Name = identity.Name
+	return nil
+}
+
+// registerParty adds a party to the registry as Pending. The party itself or an authority of its country can register it.
+func (s *SmartContract) registerParty(ctx contractapi.TransactionContextInterface, transaction string, role string, ID string, Name string, Country string) (bool, error) {
+	party := Party{Role: role, ID: strings.TrimSpace(ID), Name: strings.TrimSpace(Name), Country: strings.TrimSpace(Country), Status: PartyPending}
+	if party.ID == "" || party.Name == "" || party.Country == "" {
+		return false, fmt.Errorf("the ID, the name, and the country of the %s can not be empty", role)
+	}
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !caller.manages(&party) {
+		return false, fmt.Errorf("access denied: caller with role %s from %s can not call %s for %s", caller.Role, caller.MSPID, transaction, partyLabel(role, party.ID))
+	}
+	existing, err := readParty(ctx, role, party.ID)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, fmt.Errorf("%s is already registered. Use UpdateParty to change it", partyLabel(role, party.ID))
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	party.RegisteredBy = caller.MSPID
+	party.RegisteredDate = currentDate.Format(dateFormat)
+	if role == RoleEmployee {
+		party.Name = "" // Kept in the private collection by RegisterEmployee.
+	}
+	if err := putParty(ctx, &party); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will register an employer as Pending, until an authority of its country verifies it with SetPartyStatus.
+* Only the employer itself, or an authority of its country, can register it.
+ */
+func (s *SmartContract) RegisterEmployer(ctx contractapi.TransactionContextInterface, ID string, Name string, Country string) (bool, error) {
+	return s.registerParty(ctx, "RegisterEmployer", RoleEmployer, ID, Name, Country)
+}
+
+/*
+* This method will register an employee as Pending, until an authority of their country verifies them with SetPartyStatus.
+* The ID and the name are read from the transient map under employee_identity, and the employee is registered under the pseudonym of the ID.
+* Only the employee themselves, or an authority of their country, can register them.
+ */
+func (s *SmartContract) RegisterEmployee(ctx contractapi.TransactionContextInterface, Country string) (bool, error) {
+	identity, err := readIdentityTransient(ctx)
+	if err != nil {
+		return false, err
+	}
+	pseudonym, err := employeePseudonym(ctx, identity.ID)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.registerParty(ctx, "RegisterEmployee", RoleEmployee, pseudonym, identity.Name, Country); err != nil {
+		return false, err
+	}
+	if err := putEmployeeIdentity(ctx, pseudonym, identity); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will change the name or the country of a registered party. The party goes back to Pending
+* and must be verified again. Contracts already written keep the details they were created with.
+* @Param Role is either employer or employee.
+* @Param ID is the employee pseudonym for employees, or their cleartext ID, see lookupPartyID. Their Name must be empty, and the new one is read from the transient map
+* under employee_identity with their cleartext ID.
+* @Param ExpectedRevision is the revision of the party the caller read.
+* Only the party itself, or an authority of its current country, can change it.
+ */
+func (s *SmartContract) UpdateParty(ctx contractapi.TransactionContextInterface, Role string, ID string, Name string, Country string, ExpectedRevision int) (bool, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	ID, err = lookupPartyID(ctx, caller, Role, ID)
+	if err != nil {
+		return false, err
+	}
+	party, err := readParty(ctx, Role, ID)
+	if err != nil {
+		return false, err
+	}
+	if party == nil {
+		return false, fmt.Errorf("%s is not registered", partyLabel(Role, ID))
+	}
+	if !caller.manages(party) {
+		return false, fmt.Errorf("access denied: caller with role %s from %s can not call UpdateParty for %s", caller.Role, caller.MSPID, partyLabel(Role, ID))
+	}
+	if err := checkRevision("party", ID, party.Revision, ExpectedRevision); err != nil {
+		return false, err
+	}
+	var identity *EmployeeIdentity
+	if Role == RoleEmployee {
+		if Name != "" {
+			return false, fmt.Errorf("the name of an employee must be passed in the transient map under %s", identityTransientKey)
+		}
+		if identity, err = readIdentityTransient(ctx); err != nil {
+			return false, err
+		}
+		pseudonym, err := employeePseudonym(ctx, identity.ID)
+		if err != nil {
+			return false, err
+		}
+		if pseudonym != party.ID {
+			return false, fmt.Errorf("the employee ID in the transient map is not the one of the employee being changed")
+		}
+		Name = identity.Name
+	}
+	Name, Country = strings.TrimSpace(Name), strings.TrimSpace(Country)
+	if Name == "" || Country == "" {
+		return false, fmt.Errorf("the name and the country of the %s can not be empty", Role)
+	}
+
+	party.Name = Name
+	if identity != nil {
+		party.Name = ""
+		if err := putEmployeeIdentity(ctx, party.ID, identity); err != nil {
+			return false, err
+		}
+	}
+	party.Country = Country
+	party.Status = PartyPending
+	party.StatusBy = ""
+	party.StatusDate = ""
+	if err := putParty(ctx, party); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will verify or reject a registered party after its details were checked.
+* @Param Status is either Verified or Rejected. A Verified party can be Rejected later to stop new contracts naming it.
+* @Param ExpectedRevision is the revision of the party the authority checked.
+* Only authorities of the party's country can run it.
+ */
+func (s *SmartContract) SetPartyStatus(ctx contractapi.TransactionContextInterface, Role string, ID string, Status string, ExpectedRevision int) (bool, error) {
+	status := PartyStatus(Status)
+	if status != PartyVerified && status != PartyRejected {
+		return false, fmt.Errorf("unknown party status: %s. It can only be Verified or Rejected", Status)
+	}
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	ID, err = lookupPartyID(ctx, caller, Role, ID)
+	if err != nil {
+		return false, err
+	}
+	party, err := readParty(ctx, Role, ID)
+	if err != nil {
+		return false, err
+	}
+	if party == nil {
+		return false, fmt.Errorf("%s is not registered", partyLabel(Role, ID))
+	}
+	if caller.Role != RoleAuthority || caller.MSPID != countryMSP(party.Country) {
+		return false, fmt.Errorf("access denied: caller with role %s from %s can not call SetPartyStatus for %s", caller.Role, caller.MSPID, partyLabel(Role, ID))
+	}
+	if err := checkRevision("party", ID, party.Revision, ExpectedRevision); err != nil {
+		return false, err
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	party.Status = status
+	party.StatusBy = caller.MSPID
+	party.StatusDate = currentDate.Format(dateFormat)
+	if err := putParty(ctx, party); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will return a registered party.
+* @Param Role is either employer or employee.
+* @Param ID is the pseudonym of an employee. The employee themselves and the authorities of their country can also pass the cleartext ID,
+* see lookupPartyID. The employee name is only returned to them.
+ */
+func (s *SmartContract) GetParty(ctx contractapi.TransactionContextInterface, Role string, ID string) (*Party, error) {
+	// Any enrolled identity with a valid role can read the registry, since contracts are checked against it.
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ID, err = lookupPartyID(ctx, caller, Role, ID)
+	if err != nil {
+		return nil, err
+	}
+	party, err := readParty(ctx, Role, ID)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil {
+		return nil, fmt.Errorf("%s is not registered", partyLabel(Role, ID))
+	}
+	if Role == RoleEmployee && caller.manages(party) {
+		if err := loadPartyName(ctx, party); err != nil {
+			return nil, err
+		}
+	}
+	return party, nil
+}
@@ -0,0 +1,41 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RevisionConflictError is returned when a transaction was built from an older revision of a contract or a dispute
+// than the one in the world state. The client should read the record again and reapply its change.
+type RevisionConflictError struct {
+	Record   string // Either contract, dispute, rule pack, or party.
+	ID       string
+	Expected int
+	Actual   int
+}
+
+func (e *RevisionConflictError) Error() string {
+	return fmt.Sprintf("revision conflict: the %s %s is at revision %d, but the change was made against revision %d", e.Record, e.ID, e.Actual, e.Expected)
+}
+
+// checkRevision will return a RevisionConflictError if the expected revision is not the current one.
+// Contracts and disputes written before revisions were added are at revision 0.
+func checkRevision(record string, ID string, actual int, expected int) error {
+	if actual != expected {
+		return &RevisionConflictError{Record: record, ID: ID, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// putContract increases the contract revision and writes the contract to the world state.
+// Every write of a contract record must go through it, so the revision never goes back.
+func putContract(ctx contractapi.TransactionContextInterface, contract *Contract) error {
+	contract.Revision++
+	contractJson, err := json.Marshal(contract)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(contract.ID, contractJson)
+}
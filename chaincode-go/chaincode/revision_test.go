@@ -0,0 +1,43 @@
+package chaincode
+
+import (
+	"errors"
+	"testing"
+)
+
+// wantConflict fails the test unless err is a revision conflict on the given contract.
+func wantConflict(t *testing.T, err error, ID string) {
+	t.Helper()
+	var conflict *RevisionConflictError
+	if !errors.As(err, &conflict) || conflict.Record != "contract" || conflict.ID != ID {
+		t.Fatalf("want a revision conflict on contract %s, got %v", ID, err)
+	}
+}
+
+func TestStaleContractRevision(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+	l.addContract("11930", "01/02/2026", "05/05/2028")
+	l.sign(testEmployer, "11930")
+	stale := l.read("11930").Revision - 1
+
+	// The signature is checked after the revision, so any signature will do.
+	_, err := l.contract.SignContract(l.as(testEmployee), "11930", "c2lnbmF0dXJl", stale)
+	wantConflict(t, err, "11930")
+
+	l.sign(testEmployee, "11930")
+	_, err = l.contract.ApproveContract(l.as(testAuthorityA), "11930", stale)
+	wantConflict(t, err, "11930")
+	l.approve(testAuthorityA, "11930")
+	l.approve(testAuthorityB, "11930")
+
+	stale = l.read("11930").Revision - 1
+	_, err = l.contract.IssueDispute(l.as(testEmployee), "11930", "wages", "high", "The salary of February is late", stale)
+	wantConflict(t, err, "11930")
+
+	l.proposeNotes("11930", "Remote", "03/01/2026")
+	_, err = l.contract.AcceptAmendment(l.as(testEmployee), "11930", "0", stale)
+	wantConflict(t, err, "11930")
+	if status := l.amendment("11930", "0").Status; status != "Pending" {
+		t.Fatalf("want the amendment still Pending, got %s", status)
+	}
+}
@@ -0,0 +1,340 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// IncreaseType: how the salary of a contract grows every year.
+type IncreaseType string
+
+const (
+	IncreaseNone    IncreaseType = "none"
+	IncreasePercent IncreaseType = "percent" // Percent of the salary in force.
+	IncreaseFixed   IncreaseType = "fixed"   // A fixed amount in the contract currency.
+)
+
+// AnnualIncrease: the rule the salary of a contract grows by. The increase applies on the 1st of the anniversary month,
+// once a year of service has been completed counting from the start month, and then every year.
+type AnnualIncrease struct {
+	Type             IncreaseType `json:"Type"`
+	Percent          float64      `json:"Percent,omitempty" metadata:"Percent,optional"`                     // Only for percent increases. 5 means 5%.
+	Amount           *Money       `json:"Amount,omitempty" metadata:"Amount,optional"`                       // Only for fixed increases.
+	AnniversaryMonth int          `json:"Anniversary month,omitempty" metadata:"Anniversary month,optional"` // 1 to 12. Empty means the month of the start date.
+	Cap              *Money       `json:"Cap,omitempty" metadata:"Cap,optional"`                             // The salary never grows above it. Empty means no cap.
+	Description      string       `json:"Description,omitempty" metadata:"Description,optional"`             // Free text, kept from contracts written before the rule was typed.
+}
+
+// UnmarshalJSON also accepts the free text contracts had before the increase was typed, such as "5%".
+// See parseIncrease for how the text is read.
+func (a *AnnualIncrease) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*a = parseIncrease(text)
+		return nil
+	}
+	type plainIncrease AnnualIncrease // Has no UnmarshalJSON, so it doesn't loop.
+	var increase plainIncrease
+	if err := json.Unmarshal(data, &increase); err != nil {
+		return err
+	}
+	*a = AnnualIncrease(increase)
+	return nil
+}
+
+// parseIncrease reads the free text annual increase of older contracts. A percentage such as "5%" becomes a percent increase,
+// and a number such as "500" a fixed one, in major units of the contract currency (see Benefits.migrate). Any other text is kept as the description of an increase of type none,
+// so the salary is projected without increases. An empty text stays empty.
+func parseIncrease(text string) AnnualIncrease {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return AnnualIncrease{}
+	}
+	if number := strings.TrimSpace(strings.TrimSuffix(trimmed, "%")); number != trimmed {
+		if percent, err := strconv.ParseFloat(number, 64); err == nil && percent > 0 {
+			return AnnualIncrease{Type: IncreasePercent, Percent: percent, Description: text}
+		}
+	} else if amount, err := strconv.ParseInt(trimmed, 10, 64); err == nil && amount > 0 {
+		return AnnualIncrease{Type: IncreaseFixed, Amount: &Money{MinorUnits: amount}, Description: text}
+	}
+	return AnnualIncrease{Type: IncreaseNone, Description: text}
+}
+
+// validate will return an error if the rule can't be applied to the given starting salary.
+func (a AnnualIncrease) validate(salary Money) error {
+	switch a.Type {
+	case IncreaseNone:
+	case IncreasePercent:
+		if a.Percent <= 0 || a.Percent > 100 {
+			return fmt.Errorf("the percent of the annual increase must be more than 0 and at most 100")
+		}
+	case IncreaseFixed:
+		if a.Amount == nil || a.Amount.MinorUnits <= 0 {
+			return fmt.Errorf("the amount of the annual increase must be more than 0")
+		}
+	default:
+		return fmt.Errorf("unknown annual increase type: %s. It can only be none, percent, or fixed", a.Type)
+	}
+	if a.AnniversaryMonth < 0 || a.AnniversaryMonth > 12 {
+		return fmt.Errorf("the anniversary month must be between 1 and 12, or empty for the month of the start date")
+	}
+	if a.Cap != nil && a.Cap.MinorUnits < salary.MinorUnits {
+		return fmt.Errorf("the cap of the annual increase can't be lower than the salary")
+	}
+	return nil
+}
+
+// Will return the salary after one increase. The percentage is applied in basis points, so every peer rounds the same way.
+func (a AnnualIncrease) apply(salary Money) Money {
+	increased := salary
+	switch a.Type {
+	case IncreasePercent:
+		basisPoints := int64(math.Round(a.Percent * 100))
+		increased.MinorUnits += (salary.MinorUnits*basisPoints + 5000) / 10000
+	case IncreaseFixed:
+		if a.Amount != nil {
+			increased.MinorUnits += a.Amount.MinorUnits
+		}
+	}
+	if a.Cap != nil && increased.MinorUnits > a.Cap.MinorUnits {
+		increased.MinorUnits = a.Cap.MinorUnits
+		if salary.MinorUnits > a.Cap.MinorUnits {
+			increased = salary
+		}
+	}
+	return increased
+}
+
+// Will return the first date the increase applies on for a contract that started on the given date.
+// The following ones are a year apart.
+func (a AnnualIncrease) firstDate(startDate time.Time) time.Time {
+	month := time.Month(a.AnniversaryMonth)
+	if a.AnniversaryMonth == 0 {
+		month = startDate.Month()
+	}
+	// A year of service is completed once the start month comes around again.
+	year := startDate.Year() + 1
+	if month < startDate.Month() {
+		year++
+	}
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// The salary history of every contract is kept in privateCollection under this composite key object type.
+const salaryHistoryKeyType = "contract~salary" // contract ID
+
+// SalaryChange: the monthly salary of a contract from the given date on.
+type SalaryChange struct {
+	From   string `json:"From"`
+	Salary Money  `json:"Salary"` // Older histories hold a bare number, migrated to the contract currency when read.
+}
+
+func salaryHistoryKey(ctx contractapi.TransactionContextInterface, contractID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(salaryHistoryKeyType, []string{contractID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", salaryHistoryKeyType, err)
+	}
+	return key, nil
+}
+
+// readSalaryHistory returns the salary changes of the contract ordered by date, in the given currency.
+// Will return an empty history for contracts created before salary changes were recorded.
+func readSalaryHistory(ctx contractapi.TransactionContextInterface, contractID string, currency string) ([]SalaryChange, error) {
+	key, err := salaryHistoryKey(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	historyJSON, err := ctx.GetStub().GetPrivateData(privateCollection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the salary history: %v", err)
+	}
+	history := []SalaryChange{}
+	if historyJSON == nil {
+		return history, nil
+	}
+	if err := json.Unmarshal(historyJSON, &history); err != nil {
+		return nil, err
+	}
+	for i := range history {
+		history[i].Salary.migrate(currency)
+	}
+	return history, nil
+}
+
+// recordSalaryChange adds a salary to the history of the contract. Changes from the same date or later are replaced,
+// since they were made against terms that no longer hold.
+func recordSalaryChange(ctx contractapi.TransactionContextInterface, contractID string, from string, salary Money) error {
+	fromDate, err := time.Parse(dateFormat, from)
+	if err != nil {
+		return fmt.Errorf("the salary change date must be in MM/DD/YYYY format: %v", err)
+	}
+	history, err := readSalaryHistory(ctx, contractID, salary.Currency)
+	if err != nil {
+		return err
+	}
+
+	kept := []SalaryChange{}
+	for _, change := range history {
+		changeDate, err := time.Parse(dateFormat, change.From)
+		if err == nil && changeDate.Before(fromDate) {
+			kept = append(kept, change)
+		}
+	}
+	kept = append(kept, SalaryChange{From: from, Salary: salary})
+
+	key, err := salaryHistoryKey(ctx, contractID)
+	if err != nil {
+		return err
+	}
+	historyJSON, err := json.Marshal(kept)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollection, key, historyJSON); err != nil {
+		return fmt.Errorf("failed to write the salary history: %v", err)
+	}
+	return nil
+}
+
+// salaryProjection tells the monthly salary of a contract on any date, from its salary history and its annual increase.
+type salaryProjection struct {
+	history  []SalaryChange
+	increase AnnualIncrease
+	start    time.Time
+}
+
+// newSalaryProjection reads the salary history of the contract. The private details of the contract must have been loaded,
+// because contracts created before the history was kept fall back to their current salary.
+func newSalaryProjection(ctx contractapi.TransactionContextInterface, contract *Contract) (*salaryProjection, error) {
+	startDate, err := time.Parse(dateFormat, contract.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("the start date of the contract is not valid: %v", err)
+	}
+	history, err := readSalaryHistory(ctx, contract.ID, contract.Benefits.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		history = []SalaryChange{{From: contract.StartDate, Salary: contract.Benefits.Salary}}
+	}
+	return &salaryProjection{history: history, increase: contract.Benefits.AnnualIncrease, start: startDate}, nil
+}
+
+// Will return the salary in force on the given date: the last salary change on or before it,
+// raised by every annual increase since that change. Before the first change, the first salary applies.
+func (p *salaryProjection) on(date time.Time) Money {
+	salary := p.history[0].Salary
+	changeDate := p.start
+	for _, change := range p.history {
+		from, err := time.Parse(dateFormat, change.From)
+		if err != nil || from.After(date) {
+			break
+		}
+		salary = change.Salary
+		changeDate = from
+	}
+	for increaseDate := p.increase.firstDate(p.start); !increaseDate.After(date); increaseDate = increaseDate.AddDate(1, 0, 0) {
+		if increaseDate.After(changeDate) {
+			salary = p.increase.apply(salary)
+		}
+	}
+	return salary
+}
+
+// Will return the contract year the date falls in, counting from 1. Years start on the increase dates.
+func (p *salaryProjection) year(date time.Time) int {
+	year := 1
+	for increaseDate := p.increase.firstDate(p.start); !increaseDate.After(date); increaseDate = increaseDate.AddDate(1, 0, 0) {
+		year++
+	}
+	return year
+}
+
+// SalaryYear: the pay of the contract over one contract year. A year is split in two if an amendment changes the salary within it.
+type SalaryYear struct {
+	Year            int    `json:"Year"` // Counting from 1. Each year after the first starts on an annual increase date.
+	From            string `json:"From"`
+	To              string `json:"To"`
+	Months          int    `json:"Months"`
+	MonthlySalary   Money  `json:"Monthly salary"`
+	MonthlyGrossPay Money  `json:"Monthly gross pay"` // The salary, housing, and allowances.
+	GrossPay        Money  `json:"Gross pay"`
+}
+
+// SalarySchedule: the projected pay of a contract from its start date to its end date.
+type SalarySchedule struct {
+	ContractID     string         `json:"Contract ID"`
+	Currency       string         `json:"Currency"`
+	AnnualIncrease AnnualIncrease `json:"Annual increase"`
+	Years          []SalaryYear   `json:"Years"`
+	TotalGrossPay  Money          `json:"Total gross pay"`
+}
+
+/*
+* This method will project the pay of the contract year by year, from its start date to its end date, including extensions.
+* Like GetArrears, every month of the contract counts in full, with the salary in force on its last day.
+* Housing and allowances are the current ones.
+ */
+func (s *SmartContract) GetSalarySchedule(ctx contractapi.TransactionContextInterface, ID string) (*SalarySchedule, error) {
+	contract, err := readContractFor(ctx, "GetSalarySchedule", ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadPrivateDetails(ctx, contract); err != nil {
+		return nil, err
+	}
+	projection, err := newSalaryProjection(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := time.Parse(dateFormat, contract.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("the end date of the contract is not valid: %v", err)
+	}
+
+	schedule := SalarySchedule{
+		ContractID:     contract.ID,
+		Currency:       contract.Benefits.Currency,
+		AnnualIncrease: contract.Benefits.AnnualIncrease,
+		Years:          []SalaryYear{},
+		TotalGrossPay:  Money{Currency: contract.Benefits.Currency},
+	}
+	allowances := contract.Benefits.Housing.plus(contract.Benefits.Allowances)
+	month := time.Date(projection.start.Year(), projection.start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for ; !month.After(endDate); month = month.AddDate(0, 1, 0) {
+		lastDay := month.AddDate(0, 1, -1)
+		salary := projection.on(lastDay)
+		year := projection.year(lastDay)
+
+		current := len(schedule.Years) - 1
+		if current < 0 || schedule.Years[current].Year != year || schedule.Years[current].MonthlySalary != salary {
+			from := month
+			if from.Before(projection.start) {
+				from = projection.start
+			}
+			schedule.Years = append(schedule.Years, SalaryYear{
+				Year:            year,
+				From:            from.Format(dateFormat),
+				MonthlySalary:   salary,
+				MonthlyGrossPay: salary.plus(allowances),
+				GrossPay:        Money{Currency: salary.Currency},
+			})
+			current++
+		}
+		to := lastDay
+		if to.After(endDate) {
+			to = endDate
+		}
+		schedule.Years[current].To = to.Format(dateFormat)
+		schedule.Years[current].Months++
+		schedule.Years[current].GrossPay = schedule.Years[current].GrossPay.plus(salary.plus(allowances))
+		schedule.TotalGrossPay = schedule.TotalGrossPay.plus(salary.plus(allowances))
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,139 @@
+package chaincode
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseIncrease(t *testing.T) {
+	tests := []struct {
+		text string
+		want AnnualIncrease
+	}{
+		{"", AnnualIncrease{}},
+		{"5%", AnnualIncrease{Type: IncreasePercent, Percent: 5, Description: "5%"}},
+		{" 2.5 % ", AnnualIncrease{Type: IncreasePercent, Percent: 2.5, Description: " 2.5 % "}},
+		{"500", AnnualIncrease{Type: IncreaseFixed, Amount: &Money{MinorUnits: 500}, Description: "500"}},
+		{"0%", AnnualIncrease{Type: IncreaseNone, Description: "0%"}},
+		{"-200", AnnualIncrease{Type: IncreaseNone, Description: "-200"}},
+		{"By performance", AnnualIncrease{Type: IncreaseNone, Description: "By performance"}},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if got := parseIncrease(test.text); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("want %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAnnualIncreaseValidate(t *testing.T) {
+	salary := Money{MinorUnits: 1000000, Currency: "SAR"}
+
+	tests := []struct {
+		name     string
+		increase AnnualIncrease
+		wantErr  string
+	}{
+		{"none", AnnualIncrease{Type: IncreaseNone}, ""},
+		{"percent", AnnualIncrease{Type: IncreasePercent, Percent: 3}, ""},
+		{"fixed", AnnualIncrease{Type: IncreaseFixed, Amount: &Money{MinorUnits: 50000, Currency: "SAR"}}, ""},
+		{"cap above the salary", AnnualIncrease{Type: IncreasePercent, Percent: 3, Cap: &Money{MinorUnits: 1200000, Currency: "SAR"}}, ""},
+		{"no percent", AnnualIncrease{Type: IncreasePercent}, "the percent of the annual increase must be more than 0 and at most 100"},
+		{"more than 100 percent", AnnualIncrease{Type: IncreasePercent, Percent: 101}, "the percent of the annual increase must be more than 0 and at most 100"},
+		{"no amount", AnnualIncrease{Type: IncreaseFixed}, "the amount of the annual increase must be more than 0"},
+		{"unknown type", AnnualIncrease{Type: "bonus"}, "unknown annual increase type: bonus"},
+		{"anniversary month 13", AnnualIncrease{Type: IncreaseNone, AnniversaryMonth: 13}, "the anniversary month must be between 1 and 12"},
+		{"cap below the salary", AnnualIncrease{Type: IncreasePercent, Percent: 3, Cap: &Money{MinorUnits: 900000, Currency: "SAR"}}, "the cap of the annual increase can't be lower than the salary"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wantError(t, test.increase.validate(salary), test.wantErr)
+		})
+	}
+}
+
+func TestAnnualIncreaseApply(t *testing.T) {
+	sar := func(minorUnits int64) *Money { return &Money{MinorUnits: minorUnits, Currency: "SAR"} }
+
+	tests := []struct {
+		name     string
+		increase AnnualIncrease
+		salary   int64
+		want     int64
+	}{
+		{"none", AnnualIncrease{Type: IncreaseNone}, 1000000, 1000000},
+		{"3 percent", AnnualIncrease{Type: IncreasePercent, Percent: 3}, 1000000, 1030000},
+		{"rounded half up", AnnualIncrease{Type: IncreasePercent, Percent: 2.5}, 1030, 1056},
+		{"fractions of a basis point", AnnualIncrease{Type: IncreasePercent, Percent: 3.333}, 1000000, 1033300},
+		{"fixed", AnnualIncrease{Type: IncreaseFixed, Amount: sar(50000)}, 1000000, 1050000},
+		{"up to the cap", AnnualIncrease{Type: IncreasePercent, Percent: 3, Cap: sar(1020000)}, 1000000, 1020000},
+		{"on the cap", AnnualIncrease{Type: IncreaseFixed, Amount: sar(50000), Cap: sar(1000000)}, 1000000, 1000000},
+		{"above the cap is never cut", AnnualIncrease{Type: IncreasePercent, Percent: 3, Cap: sar(900000)}, 1000000, 1000000},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.increase.apply(Money{MinorUnits: test.salary, Currency: "SAR"})
+			if got != (Money{MinorUnits: test.want, Currency: "SAR"}) {
+				t.Fatalf("want %d, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAnnualIncreaseFirstDate(t *testing.T) {
+	tests := []struct {
+		name             string
+		start            string
+		anniversaryMonth int
+		want             string
+	}{
+		{"the start month", "01/02/2026", 0, "01/01/2027"},
+		{"mid year start", "07/15/2026", 0, "07/01/2027"},
+		{"later anniversary month", "03/10/2026", 9, "09/01/2027"},
+		{"earlier anniversary month", "07/15/2026", 1, "01/01/2028"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, err := time.Parse(dateFormat, test.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			increase := AnnualIncrease{Type: IncreasePercent, Percent: 3, AnniversaryMonth: test.anniversaryMonth}
+			if got := increase.firstDate(start).Format(dateFormat); got != test.want {
+				t.Fatalf("want %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestGetSalarySchedule(t *testing.T) {
+	l := newTestLedger(t, "01/01/2026")
+	l.activeContract("11930", "01/02/2026", "05/05/2028")
+
+	sar := func(minorUnits int64) Money { return Money{MinorUnits: minorUnits, Currency: "SAR"} }
+	want := []SalaryYear{
+		{1, "01/02/2026", "12/31/2026", 12, sar(1000000), sar(1350000), sar(16200000)},
+		{2, "01/01/2027", "12/31/2027", 12, sar(1030000), sar(1380000), sar(16560000)},
+		{3, "01/01/2028", "05/05/2028", 5, sar(1060900), sar(1410900), sar(7054500)},
+	}
+
+	for _, caller := range []testIdentity{testEmployer, testEmployee, testAuthorityA} {
+		t.Run(caller.role, func(t *testing.T) {
+			schedule, err := l.contract.GetSalarySchedule(l.as(caller), "11930")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(schedule.Years, want) {
+				t.Fatalf("want %+v, got %+v", want, schedule.Years)
+			}
+			if schedule.TotalGrossPay != sar(39814500) {
+				t.Fatalf("want a total of 398145.00 SAR, got %s", schedule.TotalGrossPay)
+			}
+		})
+	}
+
+	_, err := l.contract.GetSalarySchedule(l.as(testOtherWorker), "11930")
+	wantError(t, err, "access denied")
+}
@@ -0,0 +1,413 @@
+package chaincode
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Signing keys are stored under this composite key object type, one per employer or employee.
+const signingKeyType = "party~signingkey" // role, party ID
+
+// termsFormat names the canonical serialization of ContractTerms. It changes whenever a field is added to or removed from the terms,
+// so a signature can never be read against a different set of fields than the one that was signed.
+// v2 has the pseudonym of the employee in place of their ID, and no employee name.
+const termsFormat = "contract-terms/v2"
+
+// ContractTerms: what the employer and the employee sign. Everything the parties agree on, but not the status, approvals,
+// or disputes, which change without the terms changing. The private details are covered by their hash.
+type ContractTerms struct {
+	Format             string   `json:"Format"` // Always termsFormat.
+	ID                 string   `json:"ID"`
+	Notes              string   `json:"Notes"`
+	StartDate          string   `json:"Start date"`
+	EndDate            string   `json:"End date"`
+	ExtensionDetails   string   `json:"Extension details"`
+	Employer           Employer `json:"Employer"`
+	Employee           Employee `json:"Employee"`
+	Job                Job      `json:"Job"`
+	Benefits           Benefits `json:"Benefits"`
+	PrivateDetailsHash string   `json:"Private details hash"`
+}
+
+// SigningTerms: the canonical terms of a contract and their hash, as returned by GetContractTerms.
+type SigningTerms struct {
+	ContractID string `json:"Contract ID"`
+	Terms      string `json:"Terms"`   // The canonical JSON of ContractTerms.
+	SHA256     string `json:"SHA-256"` // The hex SHA-256 of Terms. The 32 bytes it encodes are what is signed.
+}
+
+// SigningKey: the public key an employer or an employee signs contracts with.
+type SigningKey struct {
+	Role           string `json:"Role"` // Either employer or employee.
+	PartyID        string `json:"Party ID"`
+	PublicKey      string `json:"Public key"` // Base64 of the PKIX DER encoding of an ECDSA or Ed25519 key.
+	Algorithm      string `json:"Algorithm"`  // Either ECDSA or Ed25519, read from the key.
+	Version        int    `json:"Version"`    // Starts at 1 and goes up each time the party registers another key.
+	RegisteredDate string `json:"Registered date"`
+}
+
+// Signature: the signature of the employer or the employee over the SHA-256 of the contract terms.
+type Signature struct {
+	Party      string `json:"Party"` // Either employer or employee.
+	PartyID    string `json:"Party ID"`
+	TermsHash  string `json:"Terms hash"`                                            // The SHA-256 of the ContractTerms that were signed.
+	Signature  string `json:"Signature"`                                             // Base64. ASN.1 DER for ECDSA, and the raw 64 bytes for Ed25519.
+	PublicKey  string `json:"Public key"`                                            // The key the signature was checked against, in case the party registers another one later.
+	KeyVersion int    `json:"Key version,omitempty" metadata:"Key version,optional"` // The Version of that key. Empty on signatures recorded before keys had versions.
+	Date       string `json:"Date"`
+}
+
+// canonicalTerms returns the canonical serialization of the contract terms and its hex SHA-256.
+// The private details are left out, as they are in the world state, so the result is the same whoever reads the contract.
+// Go encodes struct fields in the order they are declared, so the same terms always give the same bytes.
+func canonicalTerms(contract *Contract) (string, string, error) {
+	stripped := *contract
+	stripPrivateDetails(&stripped)
+	terms := ContractTerms{
+		Format:             termsFormat,
+		ID:                 stripped.ID,
+		Notes:              stripped.Notes,
+		StartDate:          stripped.StartDate,
+		EndDate:            stripped.EndDate,
+		ExtensionDetails:   stripped.ExtensionDetails,
+		Employer:           stripped.Employer,
+		Employee:           stripped.Employee,
+		Job:                stripped.Job,
+		Benefits:           stripped.Benefits,
+		PrivateDetailsHash: stripped.PrivateDetailsHash,
+	}
+	termsJSON, err := json.Marshal(terms)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256(termsJSON)
+	return string(termsJSON), hex.EncodeToString(sum[:]), nil
+}
+
+// parsePublicKey will return an error if the key is not the base64 PKIX DER encoding of an ECDSA or Ed25519 key.
+// Returns the algorithm of the key.
+func parsePublicKey(publicKey string) (interface{}, string, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("the public key must be base64: %v", err)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, "", fmt.Errorf("the public key must be a PKIX DER key: %v", err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey:
+		return key, "ECDSA", nil
+	case ed25519.PublicKey:
+		return key, "Ed25519", nil
+	}
+	return nil, "", fmt.Errorf("unsupported public key type %T. It can only be ECDSA or Ed25519", key)
+}
+
+// verifySignature will return an error if the signature is not the one of the public key over the hex SHA-256.
+func verifySignature(publicKey string, hash string, signature string) error {
+	key, _, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	digest, err := hex.DecodeString(hash)
+	if err != nil {
+		return err
+	}
+	signed, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("the signature must be base64: %v", err)
+	}
+
+	valid := false
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		var rs struct{ R, S *big.Int }
+		if rest, err := asn1.Unmarshal(signed, &rs); err == nil && len(rest) == 0 && rs.R != nil && rs.S != nil {
+			valid = ecdsa.Verify(key, digest, rs.R, rs.S)
+		}
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, digest, signed)
+	}
+	if !valid {
+		return fmt.Errorf("the signature does not match the terms with the hash %s", hash)
+	}
+	return nil
+}
+
+func signingKeyKey(ctx contractapi.TransactionContextInterface, role string, partyID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(signingKeyType, []string{role, partyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the %s key: %v", signingKeyType, err)
+	}
+	return key, nil
+}
+
+// readSigningKey returns the key registered by the party. Will return nil if it has none.
+func readSigningKey(ctx contractapi.TransactionContextInterface, role string, partyID string) (*SigningKey, error) {
+	key, err := signingKeyKey(ctx, role, partyID)
+	if err != nil {
+		return nil, err
+	}
+	signingKeyJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if signingKeyJSON == nil {
+		return nil, nil
+	}
+	var signingKey SigningKey
+	if err := json.Unmarshal(signingKeyJSON, &signingKey); err != nil {
+		return nil, err
+	}
+	return &signingKey, nil
+}
+
+// Will return the signature of the given party, or nil if it has not signed the contract.
+func signatureOf(contract *Contract, party string) *Signature {
+	for i, signature := range contract.Signatures {
+		if signature.Party == party {
+			return &contract.Signatures[i]
+		}
+	}
+	return nil
+}
+
+// checkSignatures will return an error unless both the employer and the employee signed the current terms of the contract.
+// The signatures were checked against the keys when they were recorded, so only the hash is compared here.
+func checkSignatures(contract *Contract) error {
+	_, hash, err := canonicalTerms(contract)
+	if err != nil {
+		return err
+	}
+	for _, party := range []string{asEmployer, asEmployee} {
+		signature := signatureOf(contract, party)
+		if signature == nil {
+			return fmt.Errorf("the %s has not signed the contract %s yet", party, contract.ID)
+		}
+		if signature.TermsHash != hash {
+			return fmt.Errorf("the %s signed other terms than the current ones of contract %s, and must sign again", party, contract.ID)
+		}
+	}
+	return nil
+}
+
+// checkKeyReplaceable will return an error if a Pending contract of the party holds a signature made with its current key.
+// Replacing the key then would leave a signature on the ledger that no registered key stands behind anymore.
+func checkKeyReplaceable(ctx contractapi.TransactionContextInterface, signingKey *SigningKey) error {
+	objectType, party := employerIndex, asEmployer
+	if signingKey.Role == RoleEmployee {
+		objectType, party = employeeIndex, asEmployee
+	}
+	contracts, err := getIndexedContracts(ctx, objectType, signingKey.PartyID)
+	if err != nil {
+		return err
+	}
+	for _, contract := range contracts {
+		if contract.Status != StatusPending {
+			continue
+		}
+		if signature := signatureOf(&contract, party); signature != nil && signature.PublicKey == signingKey.PublicKey {
+			return fmt.Errorf("the Pending contract %s is signed with version %d of the key, which can not be replaced while the contract is Pending",
+				contract.ID, signingKey.Version)
+		}
+	}
+	return nil
+}
+
+/*
+* This method will register the public key the caller signs contracts with. It replaces the key registered before, if any,
+* unless a Pending contract is signed with that key. Each key registered by the party gets the next Version.
+* Signatures already recorded keep the key and the version they were made with.
+* @Param PublicKey is the base64 PKIX DER encoding of an ECDSA or Ed25519 public key.
+* Only employers and employees can run it, for their own party ID, which an authority of their country must have Verified in the registry.
+ */
+func (s *SmartContract) RegisterSigningKey(ctx contractapi.TransactionContextInterface, PublicKey string) (bool, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	if caller.Role != RoleEmployer && caller.Role != RoleEmployee {
+		return false, fmt.Errorf("access denied: caller with role %s from %s can not call RegisterSigningKey", caller.Role, caller.MSPID)
+	}
+	party, err := readParty(ctx, caller.Role, caller.PartyID)
+	if err != nil {
+		return false, err
+	}
+	if party == nil {
+		return false, fmt.Errorf("%s is not registered. It must be registered and verified before it registers a signing key", partyLabel(caller.Role, caller.PartyID))
+	}
+	if party.Status != PartyVerified {
+		return false, fmt.Errorf("%s is %s. Only Verified parties can register a signing key", partyLabel(caller.Role, caller.PartyID), party.Status)
+	}
+	if !caller.manages(party) {
+		return false, fmt.Errorf("access denied: %s is registered in %s, so it can only register a signing key from %s",
+			partyLabel(caller.Role, caller.PartyID), party.Country, countryMSP(party.Country))
+	}
+	_, algorithm, err := parsePublicKey(PublicKey)
+	if err != nil {
+		return false, err
+	}
+
+	version := 1
+	current, err := readSigningKey(ctx, caller.Role, caller.PartyID)
+	if err != nil {
+		return false, err
+	}
+	if current != nil {
+		if current.PublicKey == PublicKey {
+			return true, nil // Already registered, so the version stays the same.
+		}
+		if err := checkKeyReplaceable(ctx, current); err != nil {
+			return false, err
+		}
+		version = current.Version + 1
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	signingKey := SigningKey{
+		Role:           caller.Role,
+		PartyID:        caller.PartyID,
+		PublicKey:      PublicKey,
+		Algorithm:      algorithm,
+		Version:        version,
+		RegisteredDate: currentDate.Format(dateFormat),
+	}
+	key, err := signingKeyKey(ctx, signingKey.Role, signingKey.PartyID)
+	if err != nil {
+		return false, err
+	}
+	signingKeyJSON, err := json.Marshal(signingKey)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.GetStub().PutState(key, signingKeyJSON); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+* This method will return the signing key registered by an employer or an employee.
+* @Param Role is either employer or employee, and PartyID the Employer ID or the Employee pseudonym. The employee themselves
+* and the authorities of their country can also pass the cleartext Employee ID, see lookupPartyID.
+ */
+func (s *SmartContract) GetSigningKey(ctx contractapi.TransactionContextInterface, Role string, PartyID string) (*SigningKey, error) {
+	// Any enrolled identity with a valid role can read the keys, since they are public.
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	PartyID, err = lookupPartyID(ctx, caller, Role, PartyID)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := readSigningKey(ctx, Role, PartyID)
+	if err != nil {
+		return nil, err
+	}
+	if signingKey == nil {
+		return nil, fmt.Errorf("%s has no signing key", partyLabel(Role, PartyID))
+	}
+	return signingKey, nil
+}
+
+/*
+* This method will return the canonical terms of the contract and their hash, which the employer and the employee sign.
+ */
+func (s *SmartContract) GetContractTerms(ctx contractapi.TransactionContextInterface, ID string) (*SigningTerms, error) {
+	contract, err := readContractFor(ctx, "GetContractTerms", ID)
+	if err != nil {
+		return nil, err
+	}
+	terms, hash, err := canonicalTerms(contract)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningTerms{ContractID: contract.ID, Terms: terms, SHA256: hash}, nil
+}
+
+/*
+* This method will record the caller's signature over the current terms of a Pending contract.
+* @Param TermsSignature is the base64 signature over the 32 bytes of the hash returned by GetContractTerms,
+* made with the key the caller registered with RegisterSigningKey. Signing again replaces the caller's signature.
+* The contract can only be approved once both the employer and the employee signed its current terms.
+* @Param ExpectedRevision is the revision of the contract the caller read the terms from.
+ */
+func (s *SmartContract) SignContract(ctx contractapi.TransactionContextInterface, ID string, TermsSignature string, ExpectedRevision int) (bool, error) {
+	contract, err := readContractFor(ctx, "SignContract", ID)
+	if err != nil {
+		return false, err
+	}
+	if err := checkRevision("contract", ID, contract.Revision, ExpectedRevision); err != nil {
+		return false, err
+	}
+	if contract.Status != StatusPending {
+		return false, fmt.Errorf("Only a Pending contract can be signed. The contract is %s", contract.Status)
+	}
+
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	party := asEmployee
+	if caller.is(asEmployer, contract) {
+		party = asEmployer
+	}
+	signingKey, err := readSigningKey(ctx, caller.Role, caller.PartyID)
+	if err != nil {
+		return false, err
+	}
+	if signingKey == nil {
+		return false, fmt.Errorf("the %s %s has no signing key. Register one with RegisterSigningKey first", caller.Role, caller.PartyID)
+	}
+
+	_, hash, err := canonicalTerms(contract)
+	if err != nil {
+		return false, err
+	}
+	if err := verifySignature(signingKey.PublicKey, hash, TermsSignature); err != nil {
+		return false, err
+	}
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	signatures := []Signature{}
+	for _, signature := range contract.Signatures {
+		if signature.Party != party {
+			signatures = append(signatures, signature)
+		}
+	}
+	contract.Signatures = append(signatures, Signature{
+		Party:      party,
+		PartyID:    caller.PartyID,
+		TermsHash:  hash,
+		Signature:  TermsSignature,
+		PublicKey:  signingKey.PublicKey,
+		KeyVersion: signingKey.Version,
+		Date:       currentDate.Format(dateFormat),
+	})
+
+	if err := putContract(ctx, contract); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventContractSigned, contract, contract.Status, ""); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -13,21 +13,36 @@ import (
 // SmartContract provides functions for managing an Asset
 type SmartContract struct {
 	contractapi.Contract
+	Clock Clock // Leave empty to use the transaction timestamp.
 }
 
 // Contract: captures contract high-level information, such as its identification number, duration, and status.
 type Contract struct {
-	ID               string `json:"ID"`     // This is the ID that will identify this contract in the ledger.
-	Status           string `json:"Status"` // Can only be Pending, Active, and Terminated. In next version we will add Rejected, and Completed.
-	Notes            string `json:"Notes"`
-	StartDate        string `json:"Start date"`
-	EndDate          string `json:"End date"`
-	ExtensionDetails string `json:"Extension details"`
+	ID               string         `json:"ID"`       // This is the ID that will identify this contract in the ledger.
+	Status           ContractStatus `json:"Status"`   // See statusTransitions for the allowed changes.
+	Revision         int            `json:"Revision"` // Increased by every write, see putContract.
+	Notes            string         `json:"Notes"`
+	StartDate        string         `json:"Start date"`
+	EndDate          string         `json:"End date"`
+	ExtensionDetails string         `json:"Extension details"`
 	Employer         Employer
 	Employee         Employee
 	Job              Job
 	Benefits         Benefits
-	Disputes         []Dispute
+	Disputes         []Dispute // Filled from the dispute records when the contract is read. Empty in the world state.
+	// RequiredApprovals lists the parties that must approve before the contract becomes Active.
+	// Both are missing from contracts written before approvals were added, so they are optional.
+	RequiredApprovals []string   `json:"Required approvals,omitempty" metadata:"Required approvals,optional"`
+	Approvals         []Approval `json:"Approvals,omitempty" metadata:"Approvals,optional"`
+	// PrivateDetailsHash is the SHA-256 of the ContractPrivateDetails kept in the private collection.
+	// The salary, housing, allowances, and contact details are empty in the world state.
+	PrivateDetailsHash string `json:"Private details hash,omitempty" metadata:"Private details hash,optional"`
+	// EndedDate is the date the contract became Completed or Terminated. Salary stops being due after it.
+	EndedDate string `json:"Ended date,omitempty" metadata:"Ended date,optional"`
+	// Compliance lists the rule packs the terms were checked against, see compliance.go. Empty if neither country has rules.
+	Compliance []RulePackVersion `json:"Compliance,omitempty" metadata:"Compliance,optional"`
+	// Signatures holds the signatures of the employer and the employee over the terms, see signature.go.
+	Signatures []Signature `json:"Signatures,omitempty" metadata:"Signatures,optional"`
 }
 
 // Employer: provides data about the employer, such as name and address details
@@ -39,11 +54,14 @@ type Employer struct {
 }
 
 // Employee: provides data about the employee, such as name and contact details
+// The ID is the pseudonym of the employee and the name is empty in the world state, see pseudonym.go.
 type Employee struct {
 	ID         string `json:"ID"`
 	Name       string `json:"Name"`
 	EmployeeAC string `json:"Employee address and contact details"`
 	Country    string `json:"Country"`
+	// Identifier is the cleartext employee ID. It is only filled for callers allowed to read the private details.
+	Identifier string `json:"Identifier,omitempty" metadata:"Identifier,optional"`
 }
 
 // Job: describes the job details, such as position and task description
@@ -51,26 +69,39 @@ type Job struct {
 	Position    string `json:"Position"`
 	Level       string `json:"Level"`
 	Description string `json:"Description"`
+	// ProbationMonths is the length of the probation period. 0 means there is none.
+	ProbationMonths int `json:"Probation months,omitempty" metadata:"Probation months,optional"`
 }
 
 // Benefits: states the job benefits, such salary, allowances, and annual increase
 type Benefits struct {
-	Currency       string `json:"Currency"`
-	Salary         int    `json:"Salary"`
-	AnnualIncrease string `json:"Annual increase"`
-	AnnualLeave    string `json:"Annual leave"`
-	Housing        int    `json:"Housing"`
-	Allowances     int    `json:"Allowances"`
-	OtherBenefits  string `json:"Other benefits"`
+	Currency       string         `json:"Currency"` // ISO 4217 code. Every amount of the contract is in it, see money.go.
+	Salary         Money          `json:"Salary"`
+	AnnualIncrease AnnualIncrease `json:"Annual increase"` // See salary.go. Older contracts hold free text, which is still read.
+	AnnualLeave    AnnualLeave    `json:"Annual leave"`
+	Housing        Money          `json:"Housing"`
+	Allowances     Money          `json:"Allowances"`
+	OtherBenefits  string         `json:"Other benefits"`
 }
 
 // Disputes: lists the disputes, if any, that is raised by the employee with their content and the last update dates.
+// Each dispute is stored as its own record, see dispute.go.
 type Dispute struct {
-	ID              string `json:"ID"`
-	Status          string `json:"Status"` // Can only be Active or Closed.
-	LastUpdatedDate string `json:"Last updated date"`
-	Content         string `json:"Content"`
+	ID              string          `json:"ID"`
+	ContractID      string          `json:"Contract ID"`
+	Revision        int             `json:"Revision"` // Increased by every write, see putDispute.
+	Status          string          `json:"Status"`   // Can only be Active, Escalated, Ruled, or Closed. See arbitration.go.
+	Category        DisputeCategory `json:"Category"`
+	Severity        DisputeSeverity `json:"Severity"`
+	ResponseDueDate string          `json:"Response due date"` // Set from disputeSLAs when the dispute is issued.
+	LastUpdatedDate string          `json:"Last updated date"`
+	Content         string          `json:"Content"`
 	Responses       []Response
+	EscalatedDate   string  `json:"Escalated date,omitempty" metadata:"Escalated date,optional"`
+	Ruling          *Ruling `json:"Ruling,omitempty" metadata:"Ruling,optional"`                     // Set by RecordRuling.
+	LeaveRequestID  string  `json:"Leave request ID,omitempty" metadata:"Leave request ID,optional"` // The leave request a DisputeLeave dispute is about.
+	// ResponseDueKey is the response due date as YYYY-MM-DD, which sorts like the dates do. Set by putDispute.
+	ResponseDueKey string `json:"Response due key,omitempty" metadata:"Response due key,optional"`
 }
 
 // Responses: list the employer responses, if any, for disputes raised by the employee.
@@ -83,27 +114,28 @@ type Response struct {
 // ReadContract returns the contract stored in the world state with given id. will return nil if nothing is found.
 func (s *SmartContract) ReadContract(ctx contractapi.TransactionContextInterface, ID string) (*Contract, error) {
 
-	contractJSON, err := ctx.GetStub().GetState(ID)
+	contract, err := readContractFor(ctx, "ReadContract", ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
+		return nil, err
 	}
-	if contractJSON == nil {
-		return nil, fmt.Errorf("the contract %s does not exist", ID)
+	if err := loadPrivateDetails(ctx, contract); err != nil {
+		return nil, err
 	}
-	var contract Contract
-	err = json.Unmarshal(contractJSON, &contract)
-	if err != nil {
+	if err := loadDisputes(ctx, contract); err != nil {
 		return nil, err
 	}
-	return &contract, nil
+	return contract, nil
 }
 
 /*
 * If the jsonString is a valid contract, with matching id in the blockchain this method will return true.
 * This method will not change old disputes, responses, and status.
+* Only Pending contracts can be updated, since every required party approves again afterwards.
+* The terms of an Active or Suspended contract can only change through ProposeAmendment and AcceptAmendment.
 * @param jsonString represents The new contract.
+* @param ExpectedRevision is the revision of the contract the caller changed. The update is refused if someone else changed it since.
  */
-func (s *SmartContract) UpdateContract(ctx contractapi.TransactionContextInterface, jsonString string) (bool, error) {
+func (s *SmartContract) UpdateContract(ctx contractapi.TransactionContextInterface, jsonString string, ExpectedRevision int) (bool, error) {
 
 	//Parsing jsonString
 	jsonString = strings.ReplaceAll(jsonString, "'", "\"")
@@ -112,6 +144,12 @@ func (s *SmartContract) UpdateContract(ctx contractapi.TransactionContextInterfa
 	if err != nil {
 		return false, fmt.Errorf("Error Unmarshaling JSON: %s, \n %s", err, jsonString)
 	}
+	if err := checkArgumentBenefits([]byte(jsonString)); err != nil {
+		return false, err
+	}
+	if err := checkArgumentEmployee(&contract); err != nil {
+		return false, err
+	}
 
 	// If we don't find the contract in the blockchain we stop.
 	exists, err := s.ContractExist(ctx, contract.ID)
@@ -129,24 +167,51 @@ func (s *SmartContract) UpdateContract(ctx contractapi.TransactionContextInterfa
 	}
 	var oldContract Contract
 	json.Unmarshal(contractJSON, &oldContract)
-	if oldContract.Status == "Terminated" {
-		return false, fmt.Errorf("You can not update a terminated contract.")
+	if err := checkAccess(ctx, "UpdateContract", &oldContract); err != nil {
+		return false, err
+	}
+	if err := checkRevision("contract", oldContract.ID, oldContract.Revision, ExpectedRevision); err != nil {
+		return false, err
+	}
+	if oldContract.Status != StatusPending {
+		return false, fmt.Errorf("You can not update a %s contract. Use ProposeAmendment to change an Active or Suspended contract.", oldContract.Status)
+	}
+
+	// The salary and contact details come from the transient map, see privatedata.go.
+	private, err := readPrivateDetails(ctx)
+	if err != nil {
+		return false, err
+	}
+	mergePrivateDetails(&contract, private)
+
+	// The names and countries of the parties come from the registry, see registry.go.
+	if err := resolveParties(ctx, &contract, private); err != nil {
+		return false, err
 	}
 
 	// Each create method will check for the necessary information and conditions.
 	employer, flag1 := createEmployer(ctx, contract.Employer.ID, contract.Employer.Name, contract.Employer.EmployerAC, contract.Employer.Country)
 	employee, flag2 := createEmployee(ctx, contract.Employee.ID, contract.Employee.Name, contract.Employee.EmployeeAC, contract.Employee.Country)
-	job, flag3 := createJob(ctx, contract.Job.Position, contract.Job.Level, contract.Job.Description)
+	job, flag3 := createJob(ctx, contract.Job.Position, contract.Job.Level, contract.Job.Description, contract.Job.ProbationMonths)
 	benefits, flag4 := createBenefits(ctx, contract.Benefits.Currency, contract.Benefits.Salary, contract.Benefits.AnnualIncrease, contract.Benefits.AnnualLeave,
 		contract.Benefits.Housing, contract.Benefits.Allowances, contract.Benefits.OtherBenefits)
 
 	if !(flag1 && flag2 && flag3 && flag4) {
 		return false, fmt.Errorf("Failed to initialize one of the structs.")
 	}
+	if err := benefits.validate(); err != nil {
+		return false, err
+	}
+
+	// The caller must still be the employer after the update.
+	if err := checkAccess(ctx, "UpdateContract", &contract); err != nil {
+		return false, err
+	}
 
 	newContract := Contract{
 		ID:               contract.ID,
-		Status:           oldContract.Status, // Status only changes using TerminateContract and ApproveContract.
+		Status:           oldContract.Status, // Status only changes through the transitions in status.go.
+		Revision:         oldContract.Revision,
 		Notes:            contract.Notes,
 		StartDate:        contract.StartDate,
 		EndDate:          contract.EndDate,
@@ -156,14 +221,29 @@ func (s *SmartContract) UpdateContract(ctx contractapi.TransactionContextInterfa
 		Job:              job,
 		Benefits:         benefits,
 		Disputes:         oldContract.Disputes, // Updating Disputes & Responses is outside the scope of this method.
+		Approvals:        oldContract.Approvals,
 	}
-
-	contractJson, err := json.Marshal(newContract)
+	newContract.RequiredApprovals = requiredApprovals(&newContract)
+	// Changing the terms of a Pending contract invalidates the approvals and the signatures given so far.
+	newContract.Approvals = []Approval{}
+	newContract.Signatures = []Signature{}
+	newContract.Compliance, err = checkCompliance(ctx, &newContract)
 	if err != nil {
 		return false, err
 	}
-	err = ctx.GetStub().PutState(newContract.ID, contractJson)
-	if err != nil {
+
+	if err := putPrivateDetails(ctx, &newContract, private, newContract.StartDate); err != nil {
+		return false, err
+	}
+	stripPrivateDetails(&newContract)
+
+	if err := putContract(ctx, &newContract); err != nil {
+		return false, err
+	}
+	if err := reindexParties(ctx, &oldContract, &newContract); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventContractUpdated, &newContract, oldContract.Status, ""); err != nil {
 		return false, err
 	}
 
@@ -172,60 +252,99 @@ func (s *SmartContract) UpdateContract(ctx contractapi.TransactionContextInterfa
 }
 
 /*
-* Given an existing contract this method will append a new dispute into []Disputes, and return true.
+* Given an existing contract this method will add a new dispute record to it, and return true.
+* @Param Category must be one of wages, housing, leave, safety, termination, or other. It decides the response due date.
+* @Param Severity must be one of low, medium, high, or critical.
 * @Param Content must not be empty.
+* @Param ExpectedRevision is the revision of the contract the employee read. The dispute is refused if the contract changed since.
  */
-func (s *SmartContract) IssueDispute(ctx contractapi.TransactionContextInterface, ID string, Content string) (bool, error) {
-	curDate := time.Now()
+func (s *SmartContract) IssueDispute(ctx contractapi.TransactionContextInterface, ID string, Category string, Severity string, Content string, ExpectedRevision int) (bool, error) {
+	category, err := parseCategory(Category)
+	if err != nil {
+		return false, err
+	}
+	severity, err := parseSeverity(Severity)
+	if err != nil {
+		return false, err
+	}
+	curDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
 	dispute := Dispute{
-		ID:              "1", // should be modified later
+		ID:              "1", // Set by fileDispute.
 		Status:          "Active",
-		LastUpdatedDate: curDate.Format("01/02/2006"),
+		Category:        category,
+		Severity:        severity,
+		ResponseDueDate: responseDueDate(category, curDate),
+		LastUpdatedDate: curDate.Format(dateFormat),
 		Content:         Content,
 		Responses:       []Response{},
 	}
 
+	check := func(contract *Contract) error {
+		return checkRevision("contract", ID, contract.Revision, ExpectedRevision)
+	}
+	if err := s.fileDispute(ctx, "IssueDispute", ID, &dispute, check); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fileDispute gives the new dispute an ID and stores it on the contract. It is shared by IssueDispute and DisputeLeave.
+// @Param check is called once the caller is known to have access to the contract, and can refuse the dispute. It can be nil.
+func (s *SmartContract) fileDispute(ctx contractapi.TransactionContextInterface, transaction string, ID string, dispute *Dispute, check func(contract *Contract) error) error {
 	// If the given dispute is faulty return false.
-	if !(checkDispute(ctx, dispute)) {
-		return false, fmt.Errorf("The given dispute doesn't meet all proper conditions. ")
+	if !(checkDispute(ctx, *dispute)) {
+		return fmt.Errorf("The given dispute doesn't meet all proper conditions. ")
 	}
 
 	// if the contract doesn't exist return false.
 	contractJSON, err := ctx.GetStub().GetState(ID)
 	if err != nil || contractJSON == nil {
-		return false, fmt.Errorf("The given ID doesn't match any contract in the blockchain. ")
+		return fmt.Errorf("The given ID doesn't match any contract in the blockchain. ")
 	}
 
-	// If any old dispute shares the ID of the new dispute return false.
 	var oldContract Contract
 	json.Unmarshal(contractJSON, &oldContract)
+	if err := checkAccess(ctx, transaction, &oldContract); err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(&oldContract); err != nil {
+			return err
+		}
+	}
 
 	// Now the smart contract will give a dispute id by itself without user input.
-	dispute.ID = strconv.Itoa(len(oldContract.Disputes))
-	oldContract.Disputes = append(oldContract.Disputes, dispute)
-
-	contractJson, err := json.Marshal(oldContract)
+	dispute.ID, err = newDisputeID(ctx)
 	if err != nil {
-		return false, err
+		return err
 	}
-	err = ctx.GetStub().PutState(ID, contractJson)
-	if err != nil {
-		return false, err
+	if err := migrateDisputes(ctx, &oldContract); err != nil {
+		return err
 	}
-	return true, nil
+	if err := putDispute(ctx, ID, dispute); err != nil {
+		return err
+	}
+	return s.emitEvent(ctx, EventDisputeIssued, &oldContract, oldContract.Status, dispute.ID)
 }
 
 /*
 * Given an existing contract and a valid unique dispute this method will update the dispute, and return true.
 * Only dispute.ID must be unique. @Param Content must not be empty.
+* @Param ExpectedRevision is the revision of the dispute the employee read.
  */
-func (s *SmartContract) UpdateDispute(ctx contractapi.TransactionContextInterface, ID string, DID string, Content string) (bool, error) {
+func (s *SmartContract) UpdateDispute(ctx contractapi.TransactionContextInterface, ID string, DID string, Content string, ExpectedRevision int) (bool, error) {
 
-	curDate := time.Now()
+	curDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
 	dispute := Dispute{
 		ID:              DID,
 		Status:          "Active",
-		LastUpdatedDate: curDate.Format("01/02/2006"),
+		LastUpdatedDate: curDate.Format(dateFormat),
 		Content:         Content,
 		Responses:       []Response{},
 	}
@@ -235,40 +354,28 @@ func (s *SmartContract) UpdateDispute(ctx contractapi.TransactionContextInterfac
 		return false, fmt.Errorf("the given dispute doesn't meet all proper conditions")
 	}
 
-	// if the contract doesn't exist return false.
-	contractJSON, err := ctx.GetStub().GetState(ID)
-	if err != nil || contractJSON == nil {
-		return false, fmt.Errorf("The given ID doesn't match any contract in the blockchain. ")
-	}
-
 	// We will verify if there is a matching dispute and if found we will modify it.
-	flag := false
-	var oldContract Contract
-	json.Unmarshal(contractJSON, &oldContract)
-	for i := 0; i < len(oldContract.Disputes); i++ {
-		if oldContract.Disputes[i].ID == dispute.ID {
-			if oldContract.Disputes[i].Status == "Closed" {
-				return false, fmt.Errorf("You can't update a closed dispute.")
-			}
-			flag = true
-			oldResponse := oldContract.Disputes[i].Responses // because ldContract.Disputes[i] = dispute will override responses.
-			oldContract.Disputes[i] = dispute
-			oldContract.Disputes[i].Responses = oldResponse
-			break
-		}
+	oldContract, oldDispute, err := getContractDispute(ctx, "UpdateDispute", ID, DID, ExpectedRevision)
+	if err != nil {
+		return false, err
 	}
-
-	// If no matching dispute is found we leave.
-	if !(flag) {
-		return false, fmt.Errorf("The given DisputeID doesn't match any dispute on this contract.")
+	if oldDispute.Status == "Closed" {
+		return false, fmt.Errorf("You can't update a closed dispute.")
+	}
+	if oldDispute.Status != "Active" {
+		return false, fmt.Errorf("You can't update a dispute that was escalated to arbitration.")
 	}
+	// The category, severity, and due date are fixed when the dispute is issued.
+	dispute.Category = oldDispute.Category
+	dispute.Severity = oldDispute.Severity
+	dispute.ResponseDueDate = oldDispute.ResponseDueDate
+	dispute.Responses = oldDispute.Responses // because the new dispute would override responses.
+	dispute.Revision = oldDispute.Revision
 
-	contractJson, err := json.Marshal(oldContract)
-	if err != nil {
+	if err := putDispute(ctx, ID, &dispute); err != nil {
 		return false, err
 	}
-	err = ctx.GetStub().PutState(ID, contractJson)
-	if err != nil {
+	if err := s.emitEvent(ctx, EventDisputeUpdated, oldContract, oldContract.Status, DID); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -276,43 +383,31 @@ func (s *SmartContract) UpdateDispute(ctx contractapi.TransactionContextInterfac
 }
 
 // This method will return true if the ID and dispute.ID match an existing contract. Will return false if Dispute.status == "Closed"
-func (s *SmartContract) CloseDispute(ctx contractapi.TransactionContextInterface, ID string, DisputeID string) (bool, error) {
+// Once a dispute is escalated it can only be closed by enforcing the ruling, see arbitration.go.
+func (s *SmartContract) CloseDispute(ctx contractapi.TransactionContextInterface, ID string, DisputeID string, ExpectedRevision int) (bool, error) {
 
-	// if the contract doesn't exist return false.
-	contractJSON, err := ctx.GetStub().GetState(ID)
-	if err != nil || contractJSON == nil {
-		return false, fmt.Errorf("The given ID doesn't match any contract in the blockchain. ")
+	// The dispute must exist and not be closed yet.
+	oldContract, dispute, err := getContractDispute(ctx, "CloseDispute", ID, DisputeID, ExpectedRevision)
+	if err != nil {
+		return false, err
 	}
-
-	var oldContract Contract
-	json.Unmarshal(contractJSON, &oldContract)
-	// Will search the Contract.Disputes for a matching dispute ID. If there is a match it must be active otherwise will return false.
-	flag := false
-	for i := 0; i < len(oldContract.Disputes); i++ {
-		if oldContract.Disputes[i].ID == DisputeID {
-			if oldContract.Disputes[i].Status == "Active" {
-				curDate := time.Now()
-				oldContract.Disputes[i].Status = "Closed"
-				oldContract.Disputes[i].LastUpdatedDate = curDate.Format("01/02/2006")
-				flag = true
-				break
-			} else {
-				return false, fmt.Errorf("This contract is already closed.")
-			}
-		}
+	if dispute.Status == "Closed" {
+		return false, fmt.Errorf("This dispute is already closed.")
 	}
-
-	// Flag will be set to true if we find an active dispute with a matching id.
-	if !flag {
-		return false, fmt.Errorf("There is no matching disputes with the given ID: %s ", DisputeID)
+	if dispute.Status == "Escalated" || dispute.Status == "Ruled" {
+		return false, fmt.Errorf("The dispute is %s. Only EnforceRuling can close it now.", dispute.Status)
 	}
-
-	contractJson, err := json.Marshal(oldContract)
+	curDate, err := s.now(ctx)
 	if err != nil {
 		return false, err
 	}
-	err = ctx.GetStub().PutState(ID, contractJson)
-	if err != nil {
+	dispute.Status = "Closed"
+	dispute.LastUpdatedDate = curDate.Format(dateFormat)
+
+	if err := putDispute(ctx, ID, dispute); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventDisputeClosed, oldContract, oldContract.Status, DisputeID); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -320,11 +415,15 @@ func (s *SmartContract) CloseDispute(ctx contractapi.TransactionContextInterface
 
 // This method responds to an open dispute.
 // Given an existing ID and DisputeID, and a unique RespondID this method will return true. @Param Content must not be empty.
-func (s *SmartContract) RespondToDispute(ctx contractapi.TransactionContextInterface, ID string, DisputeID string, Content string) (bool, error) {
-	curDate := time.Now()
+// @Param ExpectedRevision is the revision of the dispute the employer read.
+func (s *SmartContract) RespondToDispute(ctx contractapi.TransactionContextInterface, ID string, DisputeID string, Content string, ExpectedRevision int) (bool, error) {
+	curDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
 	response := Response{
 		ID:              "Empty",
-		LastUpdatedDate: curDate.Format("01/02/2006"),
+		LastUpdatedDate: curDate.Format(dateFormat),
 		Content:         Content,
 	}
 
@@ -333,42 +432,27 @@ func (s *SmartContract) RespondToDispute(ctx contractapi.TransactionContextInter
 	if !flag {
 		return false, fmt.Errorf("The response structure is invalid.")
 	}
-	contractJSON, err := ctx.GetStub().GetState(ID)
-	if err != nil || contractJSON == nil {
-		return false, fmt.Errorf("The given ID doesn't match any contract in the blockchain. ")
-	}
 
-	var contract Contract
-	json.Unmarshal(contractJSON, &contract)
-
-	// Will search the Contract.Disputes for a matching dispute ID. If there is a match it must be active otherwise will return false.
-	flag = false
-	var disputePos int
-	for i := 0; i < len(contract.Disputes); i++ {
-		if contract.Disputes[i].ID == DisputeID {
-			if contract.Disputes[i].Status == "Active" {
-				flag = true
-				disputePos = i
-				break
-			} else {
-				return false, fmt.Errorf("This contract is already closed.")
-			}
-		}
+	// The dispute must exist and not be closed yet.
+	contract, dispute, err := getContractDispute(ctx, "RespondToDispute", ID, DisputeID, ExpectedRevision)
+	if err != nil {
+		return false, err
 	}
-	// Flag will be set to true if we find an active dispute with a matching id.
-	if !flag {
-		return false, fmt.Errorf("There is no matching disputes with the given ID: %s ", DisputeID)
+	if dispute.Status == "Closed" {
+		return false, fmt.Errorf("This dispute is already closed.")
+	}
+	// The employer can respond again once the arbitrator ruled, see arbitration.go.
+	if isLocked(dispute) {
+		return false, fmt.Errorf("The dispute was escalated to arbitration and is locked until a ruling is recorded.")
 	}
 
 	// Because we don't want the user to enter response ID.
-	response.ID = strconv.Itoa(len(contract.Disputes[disputePos].Responses))
-	contract.Disputes[disputePos].Responses = append(contract.Disputes[disputePos].Responses, response)
-	contractJson, err := json.Marshal(contract)
-	if err != nil {
+	response.ID = strconv.Itoa(len(dispute.Responses))
+	dispute.Responses = append(dispute.Responses, response)
+	if err := putDispute(ctx, ID, dispute); err != nil {
 		return false, err
 	}
-	err = ctx.GetStub().PutState(ID, contractJson)
-	if err != nil {
+	if err := s.emitEvent(ctx, EventDisputeResponded, contract, contract.Status, DisputeID); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -383,6 +467,29 @@ func (s *SmartContract) HandleAddContract(ctx contractapi.TransactionContextInte
 	if err != nil {
 		return false, fmt.Errorf("Error Unmarshaling JSON: %s, \n %s", err, jsonString)
 	}
+	if err := checkArgumentBenefits([]byte(jsonString)); err != nil {
+		return false, err
+	}
+	if err := checkArgumentEmployee(&contract); err != nil {
+		return false, err
+	}
+
+	// Only the employer named in the contract can create it.
+	if err := checkAccess(ctx, "HandleAddContract", &contract); err != nil {
+		return false, err
+	}
+
+	// The salary and contact details come from the transient map, see privatedata.go.
+	private, err := readPrivateDetails(ctx)
+	if err != nil {
+		return false, err
+	}
+	mergePrivateDetails(&contract, private)
+
+	// The names and countries of the parties come from the registry, see registry.go.
+	if err := resolveParties(ctx, &contract, private); err != nil {
+		return false, err
+	}
 
 	// Check if there is an existing contract with the same ID.
 	exists, err := s.ContractExist(ctx, contract.ID)
@@ -401,36 +508,55 @@ func (s *SmartContract) HandleAddContract(ctx contractapi.TransactionContextInte
 
 	employer, flag1 := createEmployer(ctx, tempEmployer.ID, tempEmployer.Name, tempEmployer.EmployerAC, tempEmployer.Country)
 	employee, flag2 := createEmployee(ctx, tempEmployee.ID, tempEmployee.Name, tempEmployee.EmployeeAC, tempEmployee.Country)
-	job, flag3 := createJob(ctx, tempJob.Position, tempJob.Level, tempJob.Description)
+	job, flag3 := createJob(ctx, tempJob.Position, tempJob.Level, tempJob.Description, tempJob.ProbationMonths)
 	benefits, flag4 := createBenefits(ctx, tempBenefits.Currency, tempBenefits.Salary, tempBenefits.AnnualIncrease, tempBenefits.AnnualLeave,
 		tempBenefits.Housing, tempBenefits.Allowances, tempBenefits.OtherBenefits)
 
 	// in case one of the structs failed to initialize.
 	if !(flag1 && flag2 && flag3 && flag4) {
+		return false, fmt.Errorf("Failed to initialize one of the structs.")
+	}
+	if err := benefits.validate(); err != nil {
 		return false, err
 	}
 
-	flag5, err := checkDate(contract.StartDate, contract.EndDate)
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	flag5, err := checkDate(currentDate, contract.StartDate, contract.EndDate)
 	if !(flag5) {
 		return false, fmt.Errorf("Time has failed Error: %s", err)
 	}
 
 	var disputes = []Dispute{} // A new contract should have no disputes.
 
-	contract.Status = "Pending" // Every new contract should start with status as pending. Will ignore jsonString input.
+	contract.Status = StatusPending // Every new contract should start with status as pending. Will ignore jsonString input.
 	// In case one of the create method changes anything in the struct
 	contract.Employer = employer
 	contract.Employee = employee
 	contract.Job = job
 	contract.Benefits = benefits
 	contract.Disputes = disputes
-
-	contractJson, err := json.Marshal(contract)
+	contract.RequiredApprovals = requiredApprovals(&contract)
+	contract.Approvals = []Approval{} // Nobody approved the contract yet.
+	contract.Compliance, err = checkCompliance(ctx, &contract)
 	if err != nil {
 		return false, err
 	}
-	err = ctx.GetStub().PutState(contract.ID, contractJson)
-	if err != nil {
+
+	if err := putPrivateDetails(ctx, &contract, private, contract.StartDate); err != nil {
+		return false, err
+	}
+	stripPrivateDetails(&contract)
+
+	if err := putContract(ctx, &contract); err != nil {
+		return false, err
+	}
+	if err := indexContract(ctx, &contract); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventContractCreated, &contract, "", ""); err != nil {
 		return false, err
 	}
 
@@ -439,76 +565,70 @@ func (s *SmartContract) HandleAddContract(ctx contractapi.TransactionContextInte
 }
 
 /*
-* This method will change the contract status from Active to Terminated.
-* Will return true only if ID points to existing contract and said contract status is not Terminated.
+* This method will change the contract status to Terminated.
+* Will return true only if ID points to existing contract and said contract status is Pending, Active, or Suspended.
  */
 func (s *SmartContract) TerminateContract(ctx contractapi.TransactionContextInterface, ID string) (bool, error) {
-	// Retrieving the contract from world state and unmarshaling into contract.
-	contractJSON, err := ctx.GetStub().GetState(ID)
-	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if contractJSON == nil {
-		return false, fmt.Errorf("the contract %s does not exist", ID)
-	}
-	var contract Contract
-	err = json.Unmarshal(contractJSON, &contract)
-	if err != nil {
+	if _, err := s.changeStatus(ctx, ID, "TerminateContract", StatusTerminated); err != nil {
 		return false, err
 	}
+	return true, nil
+}
 
-	if contract.Status == "Terminated" {
-		return false, fmt.Errorf("The contract is already %s.", contract.Status)
-	}
-
-	contract.Status = "Terminated"
+/*
+* ID represents the contract ID.
+* This method will record the caller approval, and change the contract status from Pending to Active once every required party approved.
+* Will return true only if ID points to existing contract, said contract status is Pending, and the caller did not approve before.
+* @Param ExpectedRevision is the revision of the contract the caller reviewed.
+ */
+func (s *SmartContract) ApproveContract(ctx contractapi.TransactionContextInterface, ID string, ExpectedRevision int) (bool, error) {
 
-	contractJson, err := json.Marshal(contract)
+	contract, err := readContractFor(ctx, "ApproveContract", ID)
 	if err != nil {
 		return false, err
 	}
-	err = ctx.GetStub().PutState(contract.ID, contractJson)
-	if err != nil {
+	if err := checkRevision("contract", ID, contract.Revision, ExpectedRevision); err != nil {
 		return false, err
 	}
 
-	return true, nil
-
-}
-
-/*
-* ID represents the contract ID.
-* This method will change the contract status from Pending to Active.
-* Will return true only if ID points to existing contract and said contract status is not Terminated/Active.
- */
-func (s *SmartContract) ApproveContract(ctx contractapi.TransactionContextInterface, ID string) (bool, error) {
+	// Approvals are only collected while the contract is Pending.
+	if contract.Status != StatusPending {
+		return false, fmt.Errorf("illegal status change: the contract is %s and can not be approved", contract.Status)
+	}
+	if len(contract.RequiredApprovals) == 0 { // Contracts created before approvals were added.
+		contract.RequiredApprovals = requiredApprovals(contract)
+	}
 
-	// Retrieving the contract from world state and unmarshaling into contract.
-	contractJSON, err := ctx.GetStub().GetState(ID)
+	party, err := approvalParty(ctx, contract)
 	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %v", err)
+		return false, err
 	}
-	if contractJSON == nil {
-		return false, fmt.Errorf("the contract %s does not exist", ID)
+	if hasApproved(contract, party) {
+		return false, fmt.Errorf("%s has already approved the contract %s", party, ID)
 	}
-	var contract Contract
-	err = json.Unmarshal(contractJSON, &contract)
+	// Nobody can approve terms the employer and the employee have not both signed.
+	if err := checkSignatures(contract); err != nil {
+		return false, err
+	}
+	currentDate, err := s.now(ctx)
 	if err != nil {
 		return false, err
 	}
+	addApproval(contract, party, currentDate)
 
-	if contract.Status == "Active" || contract.Status == "Terminated" {
-		return false, fmt.Errorf("The contract is %s", contract.Status)
+	// The contract only becomes Active when the last required approval is given.
+	oldStatus := contract.Status
+	if isFullyApproved(contract) {
+		if err := checkTransition(contract.Status, StatusActive); err != nil {
+			return false, err
+		}
+		contract.Status = StatusActive
 	}
 
-	contract.Status = "Active"
-
-	contractJson, err := json.Marshal(contract)
-	if err != nil {
+	if err := putContract(ctx, contract); err != nil {
 		return false, err
 	}
-	err = ctx.GetStub().PutState(contract.ID, contractJson)
-	if err != nil {
+	if err := s.emitEvent(ctx, EventContractApproved, contract, oldStatus, ""); err != nil {
 		return false, err
 	}
 
@@ -519,39 +639,49 @@ func (s *SmartContract) ApproveContract(ctx contractapi.TransactionContextInterf
 * This method will update the contract duration.
 * @Param ToDate represents the new date at which the contract will end.
 * To return true ToDate must be further than the EndDate and currentDate should be no less than three months of currentDate.
+* The extended contract must still meet the latest rule packs of both countries, which are recorded in Compliance again.
+* @Param ExpectedRevision is the revision of the contract the employer read.
  */
-func (s *SmartContract) ExtendContract(ctx contractapi.TransactionContextInterface, ID string, ToDate string) (bool, error) {
+func (s *SmartContract) ExtendContract(ctx contractapi.TransactionContextInterface, ID string, ToDate string, ExpectedRevision int) (bool, error) {
 
-	// Retrieving the contract from world state and unmarshaling into contract.
-	contractJSON, err := ctx.GetStub().GetState(ID)
+	contract, err := readContractFor(ctx, "ExtendContract", ID)
 	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if contractJSON == nil {
-		return false, fmt.Errorf("the contract %s does not exist", ID)
+		return false, err
 	}
-	var contract Contract
-	err = json.Unmarshal(contractJSON, &contract)
-	if err != nil {
+	if err := checkRevision("contract", ID, contract.Revision, ExpectedRevision); err != nil {
 		return false, err
 	}
 
-	if !(contract.Status == "Active") {
-		return false, fmt.Errorf("The contract is not Active")
+	if contract.Status != StatusActive {
+		return false, fmt.Errorf("Only Active contracts can be extended. The contract is %s", contract.Status)
 	}
-	isCorrectDate, err := extensionCheck(ToDate, contract.EndDate)
+	currentDate, err := s.now(ctx)
+	if err != nil {
+		return false, err
+	}
+	isCorrectDate, err := extensionCheck(currentDate, ToDate, contract.EndDate)
 
 	if !isCorrectDate {
 		return false, err
 	}
 
 	contract.EndDate = ToDate
-	contractJson, err := json.Marshal(contract)
+	// The extended terms must still meet the rule packs, checked with the private amounts like a new contract.
+	private, err := getPrivateDetails(ctx, ID)
 	if err != nil {
 		return false, err
 	}
-	err = ctx.GetStub().PutState(contract.ID, contractJson)
-	if err != nil {
+	terms := *contract
+	if private != nil {
+		mergePrivateDetails(&terms, private)
+	}
+	if contract.Compliance, err = checkCompliance(ctx, &terms); err != nil {
+		return false, err
+	}
+	if err := putContract(ctx, contract); err != nil {
+		return false, err
+	}
+	if err := s.emitEvent(ctx, EventContractExtended, contract, contract.Status, ""); err != nil {
 		return false, err
 	}
 
@@ -590,23 +720,24 @@ func createEmployee(ctx contractapi.TransactionContextInterface, ID string, Name
 }
 
 // Return true if inputs are not empty.
-func createJob(ctx contractapi.TransactionContextInterface, Position string, Level string, Description string) (Job, bool) {
-	if Position == "" || Level == "" || Description == "" {
+func createJob(ctx contractapi.TransactionContextInterface, Position string, Level string, Description string, ProbationMonths int) (Job, bool) {
+	if Position == "" || Level == "" || Description == "" || ProbationMonths < 0 {
 		return Job{}, false
 	}
 
 	job := Job{
-		Position:    Position,
-		Level:       Level,
-		Description: Description,
+		Position:        Position,
+		Level:           Level,
+		Description:     Description,
+		ProbationMonths: ProbationMonths,
 	}
 	return job, true
 }
 
 // Return true if inputs are not empty, and the salary is not 0.
-func createBenefits(ctx contractapi.TransactionContextInterface, Currency string, Salary int, AnnualIncrease string, AnnualLeave string, Housing int, Allowances int, OtherBenefits string) (Benefits, bool) {
+func createBenefits(ctx contractapi.TransactionContextInterface, Currency string, Salary Money, AnnualIncrease AnnualIncrease, AnnualLeave AnnualLeave, Housing Money, Allowances Money, OtherBenefits string) (Benefits, bool) {
 	// Housing and allowances are missing because they are not as critical as the rest.
-	if Currency == "" || AnnualIncrease == "" || AnnualLeave == "" || OtherBenefits == "" || Salary == 0 {
+	if Currency == "" || AnnualIncrease.Type == "" || AnnualLeave.Days == 0 || OtherBenefits == "" || Salary.MinorUnits == 0 {
 		return Benefits{}, false
 	}
 
@@ -615,6 +746,8 @@ func createBenefits(ctx contractapi.TransactionContextInterface, Currency string
 		Salary:         Salary,
 		AnnualIncrease: AnnualIncrease,
 		AnnualLeave:    AnnualLeave,
+		Housing:        Housing,
+		Allowances:     Allowances,
 		OtherBenefits:  OtherBenefits,
 	}
 	return benefits, true
@@ -622,6 +755,11 @@ func createBenefits(ctx contractapi.TransactionContextInterface, Currency string
 
 // Will return false if the given ID doesn't match any record in the blockchain.
 func (s *SmartContract) ContractExist(ctx contractapi.TransactionContextInterface, ID string) (bool, error) {
+	// Any enrolled identity with a valid role can check if a contract exists.
+	if _, err := getCaller(ctx); err != nil {
+		return false, err
+	}
+
 	contractJSON, err := ctx.GetStub().GetState(ID)
 	if err != nil {
 		return false, fmt.Errorf("failed to read from world state: %v", err)
@@ -659,15 +797,17 @@ func createResponse(ctx contractapi.TransactionContextInterface, response Respon
 	return response, true
 }
 
-// Will check the contract start date and end date. If they are valid will return true otherwise will return false.
-func checkDate(startDate string, endDate string) (bool, error) {
-	currentDate := time.Now()
-	_, err := time.Parse("01/02/2006", startDate) // We only need to know if the startDate is in correct format or not.
-	EndDate, err1 := time.Parse("01/02/2006", endDate)
+// Will check the contract start date and end date against currentDate. If they are valid will return true otherwise will return false.
+func checkDate(currentDate time.Time, startDate string, endDate string) (bool, error) {
+	_, err := time.Parse(dateFormat, startDate) // We only need to know if the startDate is in correct format or not.
+	EndDate, err1 := time.Parse(dateFormat, endDate)
 
-	if err != nil || err1 != nil {
+	if err != nil {
 		return false, err
 	}
+	if err1 != nil {
+		return false, err1
+	}
 
 	if EndDate.Before(currentDate) {
 		return false, fmt.Errorf("You can't create a new contract in the past. Please check End date")
@@ -678,14 +818,13 @@ func checkDate(startDate string, endDate string) (bool, error) {
 }
 
 // This method will verify if the conditions of the contract allow an extension. Also will check if the new dates are valid.
-// ToDate represents the new end date we want to extend to. endDate represents the contract old date.
-func extensionCheck(toDate string, endDate string) (bool, error) {
-	currentDate := time.Now()
-	ExtendedDate, err := time.Parse("01/02/2006", toDate) // The new end date.
+// ToDate represents the new end date we want to extend to. endDate represents the contract old date. currentDate is the transaction time.
+func extensionCheck(currentDate time.Time, toDate string, endDate string) (bool, error) {
+	ExtendedDate, err := time.Parse(dateFormat, toDate) // The new end date.
 	if err != nil {
 		return false, err
 	}
-	EndDate, err := time.Parse("01/02/2006", endDate)
+	EndDate, err := time.Parse(dateFormat, endDate)
 	if err != nil {
 		return false, err
 	}
@@ -706,8 +845,29 @@ func extensionCheck(toDate string, endDate string) (bool, error) {
 	return true, nil
 }
 
-// Returns all assets found in the world state
+// Returns all assets found in the world state. Only authorities can list every contract.
 func (s *SmartContract) GetAllContracts(ctx contractapi.TransactionContextInterface) ([]*Contract, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if caller.Role != RoleAuthority {
+		return nil, fmt.Errorf("access denied: caller with role %s from %s can not call GetAllContracts", caller.Role, caller.MSPID)
+	}
+	contracts, err := s.getAllContracts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, contract := range contracts {
+		if err := loadDisputes(ctx, contract); err != nil {
+			return nil, err
+		}
+	}
+	return contracts, nil
+}
+
+// getAllContracts returns all assets found in the world state without checking the caller.
+func (s *SmartContract) getAllContracts(ctx contractapi.TransactionContextInterface) ([]*Contract, error) {
 	// range query with empty string for startKey and endKey does an
 	// open-ended query of all assets in the chaincode namespace.
 	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
@@ -734,37 +894,14 @@ func (s *SmartContract) GetAllContracts(ctx contractapi.TransactionContextInterf
 	return contracts, nil
 }
 
-// getEmployeeHistory will sort through every contract in the blockchain. Will return an array of contracts containing all contracts of an employee.
+// getEmployeeHistory will return an array containing all contracts of an employee. It uses the employee~contract index.
 func (s *SmartContract) getEmployeeHistory(ctx contractapi.TransactionContextInterface, EmployeeID string) ([]Contract, error) {
-
-	Contracts, _ := s.GetAllContracts(ctx)
-
-	var EmployeeContracts []Contract
-
-	for i := 0; i < len(Contracts); i++ {
-		if Contracts[i].Employee.ID == EmployeeID {
-			EmployeeContracts = append(EmployeeContracts, *Contracts[i])
-
-		}
-	}
-
-	return EmployeeContracts, nil
+	return getIndexedContracts(ctx, employeeIndex, EmployeeID)
 }
 
+// getEmployerHistory will return an array containing all contracts of an employer. It uses the employer~contract index.
 func (s *SmartContract) getEmployerHistory(ctx contractapi.TransactionContextInterface, EmployerID string) ([]Contract, error) {
-
-	Contracts, _ := s.GetAllContracts(ctx)
-
-	var EmployeeContracts []Contract
-
-	for i := 0; i < len(Contracts); i++ {
-		if Contracts[i].Employer.ID == EmployerID {
-			EmployeeContracts = append(EmployeeContracts, *Contracts[i])
-
-		}
-	}
-
-	return EmployeeContracts, nil
+	return getIndexedContracts(ctx, employerIndex, EmployerID)
 }
 
 // Employee data struct let us reorganize data in a way that is easy to understand.
@@ -773,28 +910,54 @@ type EmployeeData struct { // We will reuse this struct for EmployerData.
 	TerminatedContracts string
 	ActiveContracts     string
 	PendingContracts    string
+	RejectedContracts   string
+	SuspendedContracts  string
+	CompletedContracts  string
 	Disputes            string
 	OpenDisputes        string
 	ClosedDisputes      string
 }
 
-// This method will reorganize employee history.
-func (s *SmartContract) ViewEmployeeHistory(ctx contractapi.TransactionContextInterface, EmployeeID string) EmployeeData {
-	EmployeeContracts, _ := s.getEmployeeHistory(ctx, EmployeeID)
+// This method will reorganize employee history. Only the employee and authorities can view it.
+// EmployeeID is the pseudonym of the employee. The employee themselves and the authorities of their country can also pass the cleartext ID.
+func (s *SmartContract) ViewEmployeeHistory(ctx contractapi.TransactionContextInterface, EmployeeID string) (*EmployeeData, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	EmployeeID, err = lookupPartyID(ctx, caller, RoleEmployee, EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPartyAccess(ctx, "ViewEmployeeHistory", RoleEmployee, EmployeeID); err != nil {
+		return nil, err
+	}
+	EmployeeContracts, err := s.getEmployeeHistory(ctx, EmployeeID)
+	if err != nil {
+		return nil, err
+	}
 
 	var activeContracts, terminatedContracts, pendingContracts = 0, 0, 0
+	var rejectedContracts, suspendedContracts, completedContracts = 0, 0, 0
 	var totalDisputes, openDisputes, closedDisputes = 0, 0, 0
 	for i := 0; i < len(EmployeeContracts); i++ {
 		totalDisputes += len(EmployeeContracts[i].Disputes)
-		if EmployeeContracts[i].Status == "Active" {
+		switch EmployeeContracts[i].Status {
+		case StatusActive:
 			activeContracts += 1
-		} else if EmployeeContracts[i].Status == "Pending" {
+		case StatusPending:
 			pendingContracts += 1
-		} else {
+		case StatusRejected:
+			rejectedContracts += 1
+		case StatusSuspended:
+			suspendedContracts += 1
+		case StatusCompleted:
+			completedContracts += 1
+		default:
 			terminatedContracts += 1
 		}
 		for j := 0; j < len(EmployeeContracts[i].Disputes); j++ {
-			if EmployeeContracts[i].Disputes[j].Status == "Active" {
+			if EmployeeContracts[i].Disputes[j].Status != "Closed" {
 				openDisputes += 1
 			} else {
 				closedDisputes += 1
@@ -810,31 +973,48 @@ func (s *SmartContract) ViewEmployeeHistory(ctx contractapi.TransactionContextIn
 		TerminatedContracts: strconv.Itoa(terminatedContracts),
 		ActiveContracts:     strconv.Itoa(activeContracts),
 		PendingContracts:    strconv.Itoa(pendingContracts),
+		RejectedContracts:   strconv.Itoa(rejectedContracts),
+		SuspendedContracts:  strconv.Itoa(suspendedContracts),
+		CompletedContracts:  strconv.Itoa(completedContracts),
 		Disputes:            strconv.Itoa(totalDisputes),
 		OpenDisputes:        strconv.Itoa(openDisputes),
 		ClosedDisputes:      strconv.Itoa(closedDisputes),
 	}
 
-	return EmployeeData
+	return &EmployeeData, nil
 }
 
-// This method will reorganize employer history.
-func (s *SmartContract) ViewEmployerHistory(ctx contractapi.TransactionContextInterface, EmployeeID string) EmployeeData {
-	EmployeeContracts, _ := s.getEmployerHistory(ctx, EmployeeID)
+// This method will reorganize employer history. Only the employer and authorities can view it.
+func (s *SmartContract) ViewEmployerHistory(ctx contractapi.TransactionContextInterface, EmployeeID string) (*EmployeeData, error) {
+	if err := checkPartyAccess(ctx, "ViewEmployerHistory", RoleEmployer, EmployeeID); err != nil {
+		return nil, err
+	}
+	EmployeeContracts, err := s.getEmployerHistory(ctx, EmployeeID)
+	if err != nil {
+		return nil, err
+	}
 
 	var activeContracts, terminatedContracts, pendingContracts = 0, 0, 0
+	var rejectedContracts, suspendedContracts, completedContracts = 0, 0, 0
 	var totalDisputes, openDisputes, closedDisputes = 0, 0, 0
 	for i := 0; i < len(EmployeeContracts); i++ {
 		totalDisputes += len(EmployeeContracts[i].Disputes)
-		if EmployeeContracts[i].Status == "Active" {
+		switch EmployeeContracts[i].Status {
+		case StatusActive:
 			activeContracts += 1
-		} else if EmployeeContracts[i].Status == "Pending" {
+		case StatusPending:
 			pendingContracts += 1
-		} else {
+		case StatusRejected:
+			rejectedContracts += 1
+		case StatusSuspended:
+			suspendedContracts += 1
+		case StatusCompleted:
+			completedContracts += 1
+		default:
 			terminatedContracts += 1
 		}
 		for j := 0; j < len(EmployeeContracts[i].Disputes); j++ {
-			if EmployeeContracts[i].Disputes[j].Status == "Active" {
+			if EmployeeContracts[i].Disputes[j].Status != "Closed" {
 				openDisputes += 1
 			} else {
 				closedDisputes += 1
@@ -850,10 +1030,13 @@ func (s *SmartContract) ViewEmployerHistory(ctx contractapi.TransactionContextIn
 		TerminatedContracts: strconv.Itoa(terminatedContracts),
 		ActiveContracts:     strconv.Itoa(activeContracts),
 		PendingContracts:    strconv.Itoa(pendingContracts),
+		RejectedContracts:   strconv.Itoa(rejectedContracts),
+		SuspendedContracts:  strconv.Itoa(suspendedContracts),
+		CompletedContracts:  strconv.Itoa(completedContracts),
 		Disputes:            strconv.Itoa(totalDisputes),
 		OpenDisputes:        strconv.Itoa(openDisputes),
 		ClosedDisputes:      strconv.Itoa(closedDisputes),
 	}
 
-	return EmployeeData
+	return &EmployeeData, nil
 }
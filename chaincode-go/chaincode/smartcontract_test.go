@@ -0,0 +1,52 @@
+package chaincode
+
+import (
+	"testing"
+)
+
+func TestHandleAddContractDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		today   string
+		wantErr string
+	}{
+		{"before the end date", "01/01/2026", ""},
+		{"on the end date", "05/05/2028", ""},
+		{"after the end date", "05/06/2028", "You can't create a new contract in the past"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLedger(t, test.today)
+			_, err := l.contract.HandleAddContract(l.as(testEmployer), testContractJSON)
+			wantError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestExtendContract(t *testing.T) {
+	tests := []struct {
+		name    string
+		today   string
+		toDate  string
+		wantErr string
+	}{
+		{"in the last three months", "03/01/2028", "05/05/2029", ""},
+		{"too early", "01/01/2028", "05/05/2029", "You can only extend the contract in the last three months."},
+		{"shorter", "03/01/2028", "04/01/2028", "You can't shorten the length of the contract"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLedger(t, "01/01/2026")
+			l.activeContract("11930", "01/02/2026", "05/05/2028")
+			l.setDate(test.today)
+
+			_, err := l.contract.ExtendContract(l.as(testEmployer), "11930", test.toDate, l.read("11930").Revision)
+			wantError(t, err, test.wantErr)
+			if test.wantErr == "" {
+				if endDate := l.read("11930").EndDate; endDate != test.toDate {
+					t.Fatalf("want the end date %s, got %s", test.toDate, endDate)
+				}
+			}
+		})
+	}
+}
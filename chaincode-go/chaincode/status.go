@@ -0,0 +1,214 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ContractStatus: the lifecycle status of a contract. It can only change through the transitions listed in statusTransitions.
+type ContractStatus string
+
+const (
+	StatusPending    ContractStatus = "Pending"    // Waiting for the required approvals.
+	StatusActive     ContractStatus = "Active"     // Approved by every required party.
+	StatusRejected   ContractStatus = "Rejected"   // One of the required parties refused the contract.
+	StatusSuspended  ContractStatus = "Suspended"  // Put on hold by an authority, can be resumed.
+	StatusCompleted  ContractStatus = "Completed"  // Ended normally.
+	StatusTerminated ContractStatus = "Terminated" // Ended early.
+)
+
+// statusTransitions lists every status a contract can move to from its current status.
+// Rejected, Completed, and Terminated are final.
+var statusTransitions = map[ContractStatus][]ContractStatus{
+	StatusPending:    {StatusActive, StatusRejected, StatusTerminated},
+	StatusActive:     {StatusSuspended, StatusCompleted, StatusTerminated},
+	StatusSuspended:  {StatusActive, StatusTerminated},
+	StatusRejected:   {},
+	StatusCompleted:  {},
+	StatusTerminated: {},
+}
+
+// checkTransition will return an error if the contract can't move from one status to the other.
+func checkTransition(from ContractStatus, to ContractStatus) error {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("illegal status change: the contract is %s and can not become %s", from, to)
+}
+
+// Will return true if no transition leaves the given status.
+func isFinal(status ContractStatus) bool {
+	return len(statusTransitions[status]) == 0
+}
+
+// changeStatus is shared by the transactions that only move a contract to a new status.
+// It reads the contract, checks the caller against accessRules, validates the transition, and writes the contract back.
+func (s *SmartContract) changeStatus(ctx contractapi.TransactionContextInterface, ID string, transaction string, to ContractStatus) (*Contract, error) {
+	contract, err := readContractFor(ctx, transaction, ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setStatus(ctx, contract, to); err != nil {
+		return nil, err
+	}
+	return contract, nil
+}
+
+// setStatus validates the transition of a contract already read for the transaction, and writes the contract back with its new status.
+func (s *SmartContract) setStatus(ctx contractapi.TransactionContextInterface, contract *Contract, to ContractStatus) error {
+	if err := checkTransition(contract.Status, to); err != nil {
+		return err
+	}
+
+	oldStatus := contract.Status
+	contract.Status = to
+	if to == StatusCompleted || to == StatusTerminated {
+		now, err := s.now(ctx)
+		if err != nil {
+			return err
+		}
+		contract.EndedDate = now.Format(dateFormat)
+	}
+
+	if err := putContract(ctx, contract); err != nil {
+		return err
+	}
+	return s.emitEvent(ctx, statusEvents[to], contract, oldStatus, "")
+}
+
+// RejectContract changes a Pending contract to Rejected. Any required approver can reject it.
+func (s *SmartContract) RejectContract(ctx contractapi.TransactionContextInterface, ID string) (bool, error) {
+	contract, err := readContractFor(ctx, "RejectContract", ID)
+	if err != nil {
+		return false, err
+	}
+	if len(contract.RequiredApprovals) == 0 { // Contracts created before approvals were added.
+		contract.RequiredApprovals = requiredApprovals(contract)
+	}
+	if _, err := approvalParty(ctx, contract); err != nil {
+		return false, err
+	}
+
+	if err := s.setStatus(ctx, contract, StatusRejected); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SuspendContract puts an Active contract on hold.
+func (s *SmartContract) SuspendContract(ctx contractapi.TransactionContextInterface, ID string) (bool, error) {
+	if _, err := s.changeStatus(ctx, ID, "SuspendContract", StatusSuspended); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ResumeContract changes a Suspended contract back to Active.
+func (s *SmartContract) ResumeContract(ctx contractapi.TransactionContextInterface, ID string) (bool, error) {
+	if _, err := s.changeStatus(ctx, ID, "ResumeContract", StatusActive); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CompleteContract marks an Active contract as ended normally.
+func (s *SmartContract) CompleteContract(ctx contractapi.TransactionContextInterface, ID string) (bool, error) {
+	if _, err := s.changeStatus(ctx, ID, "CompleteContract", StatusCompleted); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SweepBatch: what one call of CompleteExpiredContracts did.
+type SweepBatch struct {
+	Completed []string `json:"Completed"` // The IDs of the contracts this batch changed to Completed.
+	Scanned   int      `json:"Scanned"`   // How many contracts this batch read.
+	Bookmark  string   `json:"Bookmark"`  // The key the next batch starts from. Empty once every contract has been scanned.
+}
+
+/*
+* This method will change Active contracts whose EndDate has passed to Completed, reading at most batchSize contracts from the bookmark on.
+* It emits one ContractsCompleted event with the IDs of the contracts the batch completed, if it completed any.
+* @Param batchSize is the maximum number of contracts read by one call.
+* @Param bookmark is empty for the first batch, and then the Bookmark returned by the previous one. Call it again until the Bookmark is empty.
+* Only authorities can run it.
+ */
+func (s *SmartContract) CompleteExpiredContracts(ctx contractapi.TransactionContextInterface, batchSize int, bookmark string) (*SweepBatch, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if caller.Role != RoleAuthority {
+		return nil, fmt.Errorf("access denied: caller with role %s from %s can not call CompleteExpiredContracts", caller.Role, caller.MSPID)
+	}
+	if batchSize < 1 {
+		return nil, fmt.Errorf("the batch size must be at least 1")
+	}
+
+	now, err := s.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Paginated range queries can only run in read only transactions, so the batch keeps its own bookmark:
+	// the key of the first contract it did not read. An empty end key covers every contract but skips composite keys.
+	resultsIterator, err := ctx.GetStub().GetStateByRange(bookmark, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	batch := &SweepBatch{Completed: []string{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if batch.Scanned == batchSize {
+			batch.Bookmark = queryResponse.Key
+			break
+		}
+		batch.Scanned++
+
+		var contract Contract
+		if err := json.Unmarshal(queryResponse.Value, &contract); err != nil {
+			return nil, err
+		}
+		if contract.Status != StatusActive {
+			continue
+		}
+		endDate, err := time.Parse(dateFormat, contract.EndDate)
+		if err != nil {
+			continue // A contract with a broken date can only be completed by hand.
+		}
+		// The contract is still running on its end date.
+		if now.Before(endDate.AddDate(0, 0, 1)) {
+			continue
+		}
+
+		contract.Status = StatusCompleted
+		contract.EndedDate = contract.EndDate
+		if err := putContract(ctx, &contract); err != nil {
+			return nil, err
+		}
+		batch.Completed = append(batch.Completed, contract.ID)
+	}
+
+	if len(batch.Completed) > 0 {
+		event := ContractEvent{
+			Type:        EventContractsCompleted,
+			ContractIDs: batch.Completed,
+			OldStatus:   StatusActive,
+			NewStatus:   StatusCompleted,
+		}
+		if err := s.setEvent(ctx, event); err != nil {
+			return nil, err
+		}
+	}
+	return batch, nil
+}
@@ -0,0 +1,152 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// sweepLedger has four contracts: 1 and 2 Active and ending on 05/05/2027, 3 Active and ending on 05/05/2028, and 4 still Pending.
+func sweepLedger(t *testing.T) *testLedger {
+	l := newTestLedger(t, "01/01/2026")
+	l.activeContract("1", "01/02/2026", "05/05/2027")
+	l.activeContract("2", "01/02/2026", "05/05/2027")
+	l.activeContract("3", "01/02/2026", "05/05/2028")
+	l.addContract("4", "01/02/2026", "05/05/2027")
+	return l
+}
+
+func TestCompleteExpiredContracts(t *testing.T) {
+	tests := []struct {
+		name      string
+		today     string
+		caller    testIdentity
+		batchSize int
+		want      []string
+		wantErr   string
+	}{
+		{"before the end date", "05/04/2027", testAuthorityA, 10, []string{}, ""},
+		{"on the end date", "05/05/2027", testAuthorityA, 10, []string{}, ""},
+		{"the day after the end date", "05/06/2027", testAuthorityB, 10, []string{"1", "2"}, ""},
+		{"after every end date", "01/01/2029", testAuthorityA, 10, []string{"1", "2", "3"}, ""},
+		{"employer", "01/01/2029", testEmployer, 10, nil, "access denied"},
+		{"empty batch", "01/01/2029", testAuthorityA, 0, nil, "the batch size must be at least 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := sweepLedger(t)
+			l.setDate(test.today)
+
+			batch, err := l.contract.CompleteExpiredContracts(l.as(test.caller), test.batchSize, "")
+			wantError(t, err, test.wantErr)
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(batch.Completed, test.want) {
+				t.Fatalf("want %v completed, got %v", test.want, batch.Completed)
+			}
+			if batch.Scanned != 4 || batch.Bookmark != "" {
+				t.Fatalf("want all 4 contracts scanned in one batch, got %d and the bookmark %q", batch.Scanned, batch.Bookmark)
+			}
+			for _, ID := range test.want {
+				contract := l.read(ID)
+				if contract.Status != StatusCompleted || contract.EndedDate != contract.EndDate {
+					t.Fatalf("want contract %s Completed on its end date, got %s on %q", ID, contract.Status, contract.EndedDate)
+				}
+			}
+		})
+	}
+}
+
+func TestCompleteExpiredContractsBatches(t *testing.T) {
+	tests := []struct {
+		batchSize int
+		batches   [][]string // The contracts each batch completes.
+	}{
+		{1, [][]string{{"1"}, {"2"}, {}, {}}},
+		{2, [][]string{{"1", "2"}, {}}},
+		{3, [][]string{{"1", "2"}, {}}},
+		{4, [][]string{{"1", "2"}}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("batch size %d", test.batchSize), func(t *testing.T) {
+			l := sweepLedger(t)
+			l.setDate("05/06/2027")
+
+			bookmark := ""
+			for i, want := range test.batches {
+				batch, err := l.contract.CompleteExpiredContracts(l.as(testAuthorityA), test.batchSize, bookmark)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(batch.Completed, want) {
+					t.Fatalf("batch %d: want %v completed, got %v", i, want, batch.Completed)
+				}
+				if batch.Scanned > test.batchSize {
+					t.Fatalf("batch %d: scanned %d contracts, more than the batch size", i, batch.Scanned)
+				}
+				if last := i == len(test.batches)-1; last != (batch.Bookmark == "") {
+					t.Fatalf("batch %d: got the bookmark %q", i, batch.Bookmark)
+				}
+
+				name, payload := l.lastEvent()
+				if len(want) == 0 {
+					if name == EventContractsCompleted {
+						t.Fatalf("batch %d: completed nothing, but emitted %s", i, name)
+					}
+				} else {
+					var event ContractEvent
+					if err := json.Unmarshal([]byte(payload), &event); err != nil {
+						t.Fatal(err)
+					}
+					if name != EventContractsCompleted || !reflect.DeepEqual(event.ContractIDs, want) ||
+						event.OldStatus != StatusActive || event.NewStatus != StatusCompleted {
+						t.Fatalf("batch %d: got the event %s %s", i, name, payload)
+					}
+				}
+				bookmark = batch.Bookmark
+			}
+		})
+	}
+}
+
+func TestRejectContract(t *testing.T) {
+	tests := []struct {
+		name              string
+		caller            testIdentity
+		active            bool
+		outsideCollection bool
+		wantErr           string
+	}{
+		{"authority", testAuthorityB, false, false, ""},
+		{"on a peer outside the collection", testAuthorityA, false, true, ""},
+		{"employee", testEmployee, false, false, "is not a required approver of contract 11930"},
+		{"another employee", testOtherWorker, false, false, "access denied"},
+		{"regulator", testRegulatorA, false, false, "access denied"},
+		{"Active contract", testAuthorityA, true, false, "the contract is Active and can not become Rejected"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLedger(t, "01/01/2026")
+			if test.active {
+				l.activeContract("11930", "01/02/2026", "05/05/2028")
+			} else {
+				l.addContract("11930", "01/02/2026", "05/05/2028")
+			}
+
+			l.stub.outsideCollection = test.outsideCollection
+			_, err := l.contract.RejectContract(l.as(test.caller), "11930")
+			l.stub.outsideCollection = false
+			wantError(t, err, test.wantErr)
+			if test.wantErr == "" {
+				if name, _ := l.lastEvent(); name != EventContractRejected {
+					t.Fatalf("want the event %s, got %q", EventContractRejected, name)
+				}
+				if status := l.read("11930").Status; status != StatusRejected {
+					t.Fatalf("want the contract Rejected, got %s", status)
+				}
+			}
+		})
+	}
+}